@@ -1,58 +1,200 @@
 package main
 
 import (
-	"log"
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/hardiksharma/clarityfin-api/internal/config"
 	"github.com/hardiksharma/clarityfin-api/internal/database"
 	"github.com/hardiksharma/clarityfin-api/internal/handlers"
+	"github.com/hardiksharma/clarityfin-api/internal/jobs"
+	"github.com/hardiksharma/clarityfin-api/internal/metrics"
 	"github.com/hardiksharma/clarityfin-api/internal/middleware"
 	"github.com/hardiksharma/clarityfin-api/internal/repository"
 	"github.com/hardiksharma/clarityfin-api/internal/service"
+	"github.com/hardiksharma/clarityfin-api/internal/tlsconfig"
+	"github.com/hardiksharma/clarityfin-api/internal/tracing"
+	"github.com/hardiksharma/clarityfin-api/pkg/logger"
+	"github.com/hardiksharma/clarityfin-api/pkg/phone"
+	"github.com/hardiksharma/clarityfin-api/pkg/ratelimit"
+	"github.com/hardiksharma/clarityfin-api/pkg/response"
+	"github.com/hardiksharma/clarityfin-api/pkg/validation"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func main() {
 	// 1. Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		slog.Error("failed to load configuration", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		for _, problem := range strings.Split(err.Error(), "\n") {
+			slog.Error("invalid configuration", slog.String("env", cfg.Env), slog.String("error", problem))
+		}
+		os.Exit(1)
+	}
+
+	// Structured JSON logs; every line logged with a request context carries
+	// its request ID
+	appLogger := logger.New(os.Stdout, cfg.Log.Level)
+	slog.SetDefault(appLogger)
+	appLogger.Info("configuration loaded", slog.String("env", cfg.Env))
+	if cfg.Log.Level != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Configure phone number parsing and request validation
+	if err := phone.SetDefaultRegion(cfg.Phone.DefaultRegion); err != nil {
+		fatal("invalid phone configuration", err)
+	}
+	// Enforce the `validate` tags on every bound request
+	requestValidator, err := validation.New()
+	if err != nil {
+		fatal("failed to set up request validation", err)
+	}
+	binding.Validator = requestValidator
+	binding.EnableDecoderDisallowUnknownFields = cfg.Security.StrictJSON
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("failed to set up tracing", err)
 	}
 
 	// 2. Connect to the database
-	database.Connect(cfg.Database)
+	store, err := database.Connect(cfg.Database, appLogger)
+	if err != nil {
+		fatal("failed to connect to database", err)
+	}
+	appLogger.Info("database connection opened", slog.String("driver", cfg.Database.Driver))
+
+	appMetrics := metrics.New()
+	if err := store.DB.Use(metrics.GormPlugin(appMetrics)); err != nil {
+		fatal("failed to instrument database", err)
+	}
+	if err := store.DB.Use(tracing.GormPlugin()); err != nil {
+		fatal("failed to instrument database", err)
+	}
+
+	if err := database.EnsureSchemaCurrent(store.DB); err != nil {
+		fatal("refusing to start", err)
+	}
 
 	// 3. Initialize repositories
-	userRepo := repository.NewUserRepository(database.DB)
-	subscriptionRepo := repository.NewSubscriptionRepository(database.DB)
-	otpRepo := repository.NewOTPRepository(database.DB)
+	userRepo := repository.NewUserRepository(store.DB)
+	subscriptionRepo := repository.NewSubscriptionRepository(store.DB)
+	otpRepo := repository.NewOTPRepository(store.DB)
+	idempotencyRepo := repository.NewIdempotencyRepository(store.DB)
+	uow := repository.NewUnitOfWork(store.DB)
 
 	// 4. Initialize services
-	userService := service.NewUserService(userRepo, cfg.JWT.Secret)
+	userService := service.NewUserService(userRepo, uow, cfg.JWT.Secret, appLogger, appMetrics)
 	subscriptionService := service.NewSubscriptionService(subscriptionRepo, userRepo)
-	otpService := service.NewOTPService(otpRepo, cfg.SMS)
+	otpService, err := service.NewOTPService(otpRepo, userRepo, cfg.SMS, cfg.OTP, cfg.Server.RequestTimeout, appLogger, appMetrics)
+	if err != nil {
+		fatal("failed to initialize OTP service", err)
+	}
 
 	// 5. Initialize use cases
-	userUseCase := service.NewUserUseCase(userService)
+	userUseCase := service.NewUserUseCase(userService, cfg.Auth.RequirePhoneVerification)
 	subscriptionUseCase := service.NewSubscriptionUseCase(subscriptionService)
 	otpUseCase := service.NewOTPUseCase(otpService)
 
 	// 6. Initialize handlers
-	authHandler := handlers.NewAuthHandler(userUseCase, otpUseCase)
-	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionUseCase, userService)
-	otpHandler := handlers.NewOTPHandler(otpUseCase)
+	authHandler := handlers.NewAuthHandler(userUseCase, appLogger)
+	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionUseCase, userService, cfg.Concurrency.RequireIfMatch, appLogger)
+	otpHandler := handlers.NewOTPHandler(otpUseCase, appLogger)
+	healthHandler := handlers.NewHealthHandler(2*time.Second, appLogger,
+		handlers.HealthCheck{Name: "database", Check: store.Ping},
+		handlers.HealthCheck{Name: "migrations", Check: func(ctx context.Context) error {
+			return database.EnsureSchemaCurrent(store.DB.WithContext(ctx))
+		}},
+		// Config only: the provider is not contacted, so this cannot detect an
+		// outage or revoked credentials
+		handlers.HealthCheck{Name: "sms_config", Check: func(ctx context.Context) error {
+			return cfg.SMS.Validate()
+		}},
+	)
 
 	// 7. Set up the Gin router
-	router := gin.Default()
+	router := gin.New()
+
+	// Only believe forwarding headers from known proxies; client IPs key the
+	// rate limits
+	router.RemoteIPHeaders = cfg.Server.RemoteIPHeaders
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		fatal("invalid trusted proxies", err)
+	}
 
-	// Add CORS middleware
-	router.Use(middleware.CORSMiddleware())
+	// The server span and request ID come first so every later log line can
+	// carry them. Probes and scrapes are not traced.
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+		switch c.FullPath() {
+		case "/healthz", "/readyz", "/metrics":
+			return false
+		}
+		return true
+	})))
+	router.Use(middleware.RequestID())
+	router.Use(middleware.RequestLogger(appLogger))
+	router.Use(middleware.SecurityHeaders(cfg.Security))
+	router.Use(middleware.Metrics(appMetrics))
+	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		appLogger.ErrorContext(c.Request.Context(), "panic recovered", slog.Any("panic", recovered))
+		response.InternalServerError(c)
+	}))
+
+	cors, err := middleware.CORS(cfg.CORS)
+	if err != nil {
+		fatal("invalid CORS configuration", err)
+	}
+	router.Use(cors)
+	router.Use(middleware.MaxBodySize(cfg.Server.MaxBodyBytes))
+	router.Use(middleware.RequestTimeout(cfg.Server.RequestTimeout))
+
+	// Rate limits: the global rule covers every API route and each group
+	// may add its own. Probes and scrapes are not limited.
+	rateLimitStore := ratelimit.NewMemoryStore()
+	rateLimit := func(name string, rule config.RateLimitRule) gin.HandlerFunc {
+		limiter, err := middleware.RateLimit(name, rule, rateLimitStore, appLogger)
+		if err != nil {
+			fatal("invalid rate limit configuration", err)
+		}
+		return limiter
+	}
+
+	router.NoRoute(func(c *gin.Context) {
+		response.Error(c, http.StatusNotFound, "route_not_found", "No route matches "+c.Request.Method+" "+c.Request.URL.Path)
+	})
+
+	// Health probes for the orchestrator
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+
+	// Prometheus scrape endpoint, unless it is served on the admin listener
+	if cfg.Server.Admin.Port == "" {
+		router.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+	}
 
 	// Group API routes
 	api := router.Group("/api/v1")
+	api.Use(middleware.APIKey(cfg.RateLimit.APIKeys))
+	api.Use(rateLimit("global", cfg.RateLimit.Global))
 	{
 		// Auth routes are public
 		auth := api.Group("/auth")
+		auth.Use(rateLimit("auth", cfg.RateLimit.Routes["auth"]))
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/register/otp", authHandler.RegisterWithOTP)
@@ -61,22 +203,151 @@ func main() {
 
 		// OTP routes are public
 		otp := api.Group("/otp")
+		otp.Use(rateLimit("otp", cfg.RateLimit.Routes["otp"]))
 		{
 			otp.POST("/send", otpHandler.SendOTP)
+			otp.POST("/resend", otpHandler.ResendOTP)
 			otp.POST("/verify", otpHandler.VerifyOTP)
 		}
 
 		// Subscription routes are protected
 		subs := api.Group("/subscriptions")
 		subs.Use(middleware.AuthMiddleware(cfg.JWT.Secret))
+		subs.Use(rateLimit("subscriptions", cfg.RateLimit.Routes["subscriptions"]))
+		subs.Use(middleware.Idempotency(idempotencyRepo, cfg.Idempotency.TTL, cfg.Idempotency.Lease, appLogger))
 		{
 			subs.GET("/", subscriptionHandler.GetSubscriptions)
 			subs.POST("/", subscriptionHandler.CreateSubscription)
 			subs.GET("/:id", subscriptionHandler.GetSubscriptionByID)
+			subs.PUT("/:id", subscriptionHandler.UpdateSubscription)
+			subs.PATCH("/:id", subscriptionHandler.PatchSubscription)
+			subs.DELETE("/:id", subscriptionHandler.DeleteSubscription)
 		}
 	}
 
-	// 8. Start the server
-	log.Printf("Starting server on port %s", cfg.Server.Port)
-	router.Run(":" + cfg.Server.Port)
+	// 8. Start background maintenance jobs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	scheduler := jobs.NewScheduler(cfg.Jobs.Jitter, appLogger)
+	scheduler.Register(jobs.ExpiredOTPCleanup(otpRepo, cfg.Jobs.OTPCleanupInterval))
+	scheduler.Register(jobs.ExpiredIdempotencyKeyCleanup(idempotencyRepo, cfg.Jobs.IdempotencyCleanupInterval))
+	scheduler.Start(ctx)
+
+	// 9. Start the server, with TLS if configured
+	var tlsConfig *tls.Config
+	if cfg.Server.TLS.Enabled() {
+		var reloader *tlsconfig.Reloader
+		tlsConfig, reloader, err = tlsconfig.New(cfg.Server.TLS, appLogger)
+		if err != nil {
+			fatal("invalid TLS configuration", err)
+		}
+		go reloader.Watch(ctx, cfg.Server.TLS.ReloadInterval)
+	}
+
+	servers := []*http.Server{newServer(cfg.Server, cfg.Server.Port, router, tlsConfig)}
+
+	// The admin listener serves metrics and probes on an internal port,
+	// optionally only to clients with a certificate from the admin CA
+	if cfg.Server.Admin.Port != "" {
+		adminTLS := tlsConfig
+		if cfg.Server.Admin.ClientCAFile != "" {
+			if adminTLS, err = tlsconfig.WithClientCA(tlsConfig, cfg.Server.Admin.ClientCAFile); err != nil {
+				fatal("invalid admin configuration", err)
+			}
+		}
+
+		admin := gin.New()
+		admin.Use(gin.CustomRecovery(func(c *gin.Context, recovered any) {
+			appLogger.ErrorContext(c.Request.Context(), "panic recovered", slog.Any("panic", recovered))
+			response.InternalServerError(c)
+		}))
+		admin.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+		admin.GET("/healthz", healthHandler.Liveness)
+		admin.GET("/readyz", healthHandler.Readiness)
+
+		servers = append(servers, newServer(cfg.Server, cfg.Server.Admin.Port, admin, adminTLS))
+	}
+
+	serverErr := make(chan error, len(servers))
+	for _, s := range servers {
+		go func() {
+			appLogger.Info("starting server", slog.String("addr", s.Addr), slog.Bool("tls", s.TLSConfig != nil),
+				slog.Bool("mtls", s.TLSConfig != nil && s.TLSConfig.ClientCAs != nil))
+			if err := listenAndServe(s); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- err
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		appLogger.Info("shutdown signal received")
+	case err := <-serverErr:
+		appLogger.Error("server failed", slog.String("error", err.Error()))
+	}
+	stop()
+	healthHandler.SetShuttingDown()
+
+	// Keep serving while /readyz reports shutting_down, so the orchestrator
+	// takes the instance out of rotation before the listeners close
+	if cfg.Server.ShutdownDelay > 0 {
+		appLogger.Info("waiting before shutdown", slog.Duration("shutdown_delay", cfg.Server.ShutdownDelay))
+		time.Sleep(cfg.Server.ShutdownDelay)
+	}
+
+	// 10. Stop accepting connections and drain in-flight requests
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	for _, s := range servers {
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			appLogger.Error("server did not drain in time",
+				slog.String("addr", s.Addr),
+				slog.Duration("shutdown_timeout", cfg.Server.ShutdownTimeout),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	scheduler.Stop()
+
+	if err := store.Close(); err != nil {
+		appLogger.Error("failed to close database", slog.String("error", err.Error()))
+	}
+
+	// Flush buffered spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("failed to flush traces", slog.String("error", err.Error()))
+	}
+
+	appLogger.Info("server stopped")
+}
+
+// newServer creates an HTTP server on port with the configured timeouts.
+// A non-nil tlsConfig makes it serve HTTPS.
+func newServer(cfg config.ServerConfig, port string, handler http.Handler, tlsConfig *tls.Config) *http.Server {
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// listenAndServe serves HTTPS if srv has a TLS configuration, HTTP otherwise
+func listenAndServe(srv *http.Server) error {
+	if srv.TLSConfig != nil {
+		// Certificates come from TLSConfig.GetCertificate
+		return srv.ListenAndServeTLS("", "")
+	}
+	return srv.ListenAndServe()
+}
+
+// fatal logs a startup error and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.String("error", err.Error()))
+	os.Exit(1)
 }
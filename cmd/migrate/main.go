@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/hardiksharma/clarityfin-api/internal/config"
+	"github.com/hardiksharma/clarityfin-api/internal/database"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up          apply all pending migrations
+  down [n]    roll back the last n migrations (default 1)
+  status      list migrations and whether they are applied
+`
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	store, err := database.Connect(cfg.Database, slog.Default())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer store.Close()
+
+	switch flag.Arg(0) {
+	case "up":
+		n, err := database.MigrateUp(store.DB)
+		if err != nil {
+			log.Fatalf("Migration failed after applying %d: %v", n, err)
+		}
+		log.Printf("Applied %d migrations", n)
+
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			steps, err = strconv.Atoi(flag.Arg(1))
+			if err != nil || steps < 1 {
+				log.Fatalf("Invalid step count %q", flag.Arg(1))
+			}
+		}
+		n, err := database.MigrateDown(store.DB, steps)
+		if err != nil {
+			log.Fatalf("Rollback failed after rolling back %d: %v", n, err)
+		}
+		log.Printf("Rolled back %d migrations", n)
+
+	case "status":
+		statuses, err := database.MigrationStatuses(store.DB)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02T15:04:05Z07:00")
+			}
+			fmt.Printf("%04d  %-40s  %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
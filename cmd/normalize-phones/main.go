@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"log/slog"
+
+	"github.com/hardiksharma/clarityfin-api/internal/config"
+	"github.com/hardiksharma/clarityfin-api/internal/database"
+	"github.com/hardiksharma/clarityfin-api/pkg/phone"
+)
+
+// normalize-phones rewrites stored phone numbers to E.164 and reports users
+// whose numbers collide after normalization.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report changes without writing them")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if err := phone.SetDefaultRegion(cfg.Phone.DefaultRegion); err != nil {
+		log.Fatalf("Invalid phone configuration: %v", err)
+	}
+
+	store, err := database.Connect(cfg.Database, slog.Default())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer store.Close()
+
+	report, err := database.NormalizePhoneNumbers(store.DB, *dryRun)
+	if err != nil {
+		log.Fatalf("Phone number normalization failed: %v", err)
+	}
+
+	log.Printf("Users normalized: %d", report.UsersUpdated)
+	log.Printf("OTPs normalized: %d", report.OTPsUpdated)
+	for id, raw := range report.Invalid {
+		log.Printf("Invalid phone number for user %d: %q", id, raw)
+	}
+	for _, c := range report.Collisions {
+		log.Printf("Collision on %s: users %v (stored as %q)", c.Normalized, c.UserIDs, c.Raw)
+	}
+	if *dryRun {
+		log.Println("Dry run: no changes written")
+	}
+	if len(report.Collisions) > 0 {
+		log.Fatalf("%d collisions need manual resolution", len(report.Collisions))
+	}
+}
@@ -1,27 +1,103 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"errors"
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 // Config stores all configuration for the application.
+// Env selects the profile: "dev", "staging" or "prod".
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	SMS      SMSConfig
+	Env         string
+	Server      ServerConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	Auth        AuthConfig
+	SMS         SMSConfig
+	Jobs        JobsConfig
+	Phone       PhoneConfig
+	OTP         OTPConfig
+	Log         LogConfig
+	Tracing     TracingConfig
+	Idempotency IdempotencyConfig
+	Concurrency ConcurrencyConfig
+	CORS        CORSConfig
+	RateLimit   RateLimitConfig `mapstructure:"rate_limit"`
+	Security    SecurityConfig
 }
 
+// ServerConfig controls the HTTP server. ShutdownDelay is how long the server
+// keeps serving, with /readyz failing, after a shutdown signal; ShutdownTimeout
+// is how long in-flight requests may then take to finish.
 type ServerConfig struct {
-	Port string
+	Port              string
+	RequestTimeout    time.Duration `mapstructure:"request_timeout"` // deadline applied to each request's context
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+	ShutdownDelay     time.Duration `mapstructure:"shutdown_delay"`
+	ShutdownTimeout   time.Duration `mapstructure:"shutdown_timeout"`
+	MaxBodyBytes      int64         `mapstructure:"max_body_bytes"` // 0 disables the limit
+	// TrustedProxies lists the proxy IPs or CIDRs allowed to set
+	// RemoteIPHeaders. Empty trusts none, so the client IP is the peer address.
+	TrustedProxies  []string `mapstructure:"trusted_proxies"`
+	RemoteIPHeaders []string `mapstructure:"remote_ip_headers"`
+	TLS             TLSConfig
+	Admin           AdminConfig
+}
+
+// TLSConfig enables HTTPS when CertFile and KeyFile are set. MinVersion is
+// "1.2" or "1.3"; CipherSuites are Go cipher suite names applied to TLS 1.2
+// and default to Go's secure set. The files are checked every ReloadInterval
+// and a replaced certificate is served without a restart.
+type TLSConfig struct {
+	CertFile       string        `mapstructure:"cert_file"`
+	KeyFile        string        `mapstructure:"key_file"`
+	MinVersion     string        `mapstructure:"min_version"`
+	CipherSuites   []string      `mapstructure:"cipher_suites"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"` // 0 disables reloading
+}
+
+// Enabled reports whether the server should serve HTTPS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
 }
 
+// AdminConfig controls the internal admin listener, which serves metrics
+// and health probes apart from the public API. An empty Port disables it.
+// ClientCAFile requires admin clients to present a certificate signed by
+// that CA (mutual TLS) and needs TLS to be configured.
+type AdminConfig struct {
+	Port         string `mapstructure:"port"`
+	ClientCAFile string `mapstructure:"client_ca_file"`
+}
+
+// DatabaseConfig selects the database driver and tunes the connection pool.
+// Zero values leave the database/sql defaults in place.
 type DatabaseConfig struct {
-	DSN string // Data Source Name
+	Driver             string        // "postgres" or "sqlite"
+	DSN                string        // Data Source Name
+	MaxOpenConns       int           `mapstructure:"max_open_conns"`
+	MaxIdleConns       int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime    time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime    time.Duration `mapstructure:"conn_max_idle_time"`
+	StatementTimeout   time.Duration `mapstructure:"statement_timeout"`    // Postgres only
+	SlowQueryThreshold time.Duration `mapstructure:"slow_query_threshold"` // queries slower than this are logged
 }
 
 type JWTConfig struct {
 	Secret string
 }
 
+// AuthConfig controls account registration.
+// RequirePhoneVerification rejects registration without a valid OTP.
+type AuthConfig struct {
+	RequirePhoneVerification bool `mapstructure:"require_phone_verification"`
+}
+
 type SMSConfig struct {
 	Provider string
 	Twilio   TwilioConfig
@@ -29,22 +105,171 @@ type SMSConfig struct {
 }
 
 type TwilioConfig struct {
-	AccountSID string
-	AuthToken  string
-	FromNumber string
+	AccountSID   string `mapstructure:"account_sid"`
+	AuthToken    string `mapstructure:"auth_token"`
+	FromNumber   string `mapstructure:"from_number"`   // used for SMS and voice calls
+	WhatsAppFrom string `mapstructure:"whatsapp_from"` // WhatsApp-enabled sender, e.g. "+14155238886"
 }
 
 type MSG91Config struct {
-	APIKey   string
-	SenderID string
+	APIKey   string `mapstructure:"api_key"`
+	SenderID string `mapstructure:"sender_id"`
+}
+
+// JobsConfig controls the background maintenance scheduler.
+// A zero interval disables the corresponding job.
+type JobsConfig struct {
+	Jitter                     time.Duration `mapstructure:"jitter"`
+	OTPCleanupInterval         time.Duration `mapstructure:"otp_cleanup_interval"`
+	IdempotencyCleanupInterval time.Duration `mapstructure:"idempotency_cleanup_interval"`
+}
+
+// PhoneConfig controls phone number parsing.
+// DefaultRegion is used for numbers entered without a "+" country code.
+type PhoneConfig struct {
+	DefaultRegion string `mapstructure:"default_region"`
+}
+
+// OTPConfig controls OTP message rendering and resend limits.
+// Templates maps purpose -> locale -> text/template body. Templates receive
+// .Code and .ValidMinutes.
+type OTPConfig struct {
+	DefaultLocale  string                       `mapstructure:"default_locale"`
+	AndroidAppHash string                       `mapstructure:"android_app_hash"` // appended to SMS for the Android SMS Retriever API
+	Templates      map[string]map[string]string `mapstructure:"templates"`
+	ResendCooldown time.Duration                `mapstructure:"resend_cooldown"` // minimum time between deliveries to one phone number
+	MaxResends     int                          `mapstructure:"max_resends"`     // deliveries after the first while an OTP is active
+}
+
+// LogConfig controls application logging.
+// Level is one of debug, info, warn or error.
+type LogConfig struct {
+	Level string `mapstructure:"level"`
+}
+
+// TracingConfig controls OpenTelemetry tracing.
+// Exporter is "otlp", "stdout" or "none". Endpoint is the OTLP/HTTP collector
+// address and SampleRatio the fraction of new traces that are recorded.
+type TracingConfig struct {
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	ServiceName string  `mapstructure:"service_name"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
 }
 
-// LoadConfig reads configuration from file or environment variables.
+// IdempotencyConfig controls Idempotency-Key handling.
+// TTL is how long a key and its stored response are kept. Lease is how long
+// a request still in progress holds its key before a retry may take it over;
+// it should exceed server.request_timeout.
+type IdempotencyConfig struct {
+	TTL   time.Duration `mapstructure:"ttl"`
+	Lease time.Duration `mapstructure:"lease"`
+}
+
+// ConcurrencyConfig controls optimistic concurrency on updates.
+// RequireIfMatch rejects PUT, PATCH and DELETE requests without an If-Match
+// header; otherwise the header is honoured when present.
+type ConcurrencyConfig struct {
+	RequireIfMatch bool `mapstructure:"require_if_match"`
+}
+
+// CORSConfig controls cross-origin requests from browsers.
+// AllowedOrigins are "scheme://host[:port]" values; "https://*.example.com"
+// allows every subdomain of example.com and "*" allows any origin (not
+// together with AllowCredentials). AllowedHeaders may be "*".
+type CORSConfig struct {
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+	AllowedMethods   []string      `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers"`
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age"` // how long browsers may cache a preflight
+}
+
+// RateLimitConfig controls request rate limiting. Global applies to every
+// request; Routes applies extra limits to the named route groups ("auth",
+// "otp" and "subscriptions"). APIKeys lists the hex SHA-256 digests of the
+// issued API keys that rules with key "api_key" recognize.
+type RateLimitConfig struct {
+	Global  RateLimitRule            `mapstructure:"global"`
+	Routes  map[string]RateLimitRule `mapstructure:"routes"`
+	APIKeys []string                 `mapstructure:"api_keys"`
+}
+
+// RateLimitRule is a token bucket allowing Requests per Period with bursts
+// of up to Burst (default Requests). Key selects what is limited: "ip",
+// "user" or "api_key". Zero Requests disables the rule.
+type RateLimitRule struct {
+	Requests int           `mapstructure:"requests"`
+	Period   time.Duration `mapstructure:"period"`
+	Burst    int           `mapstructure:"burst"`
+	Key      string        `mapstructure:"key"`
+}
+
+// SecurityConfig controls security response headers and request decoding.
+// A zero HSTSMaxAge disables Strict-Transport-Security. StrictJSON rejects
+// request bodies with unknown fields.
+type SecurityConfig struct {
+	HSTSMaxAge            time.Duration `mapstructure:"hsts_max_age"`
+	HSTSIncludeSubdomains bool          `mapstructure:"hsts_include_subdomains"`
+	HSTSPreload           bool          `mapstructure:"hsts_preload"`
+	FrameOptions          string        `mapstructure:"frame_options"`
+	ReferrerPolicy        string        `mapstructure:"referrer_policy"`
+	StrictJSON            bool          `mapstructure:"strict_json"`
+}
+
+// LoadConfig reads config.yaml, merges the profile file config.<env>.yaml
+// if there is one, and applies CLARITYFIN_* environment overrides. The
+// profile comes from CLARITYFIN_ENV or the env key and defaults to dev.
+// The result is not validated; call Config.Validate.
 func LoadConfig() (config Config, err error) {
 	viper.AddConfigPath(".")
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 
+	viper.SetDefault("env", EnvDev)
+	viper.SetDefault("server.request_timeout", 15*time.Second)
+	viper.SetDefault("server.read_timeout", 10*time.Second)
+	viper.SetDefault("server.read_header_timeout", 5*time.Second)
+	viper.SetDefault("server.write_timeout", 20*time.Second)
+	viper.SetDefault("server.idle_timeout", 60*time.Second)
+	viper.SetDefault("server.shutdown_delay", 5*time.Second)
+	viper.SetDefault("server.shutdown_timeout", 20*time.Second)
+	viper.SetDefault("server.max_body_bytes", 1<<20)
+	viper.SetDefault("server.remote_ip_headers", []string{"X-Forwarded-For", "X-Real-IP"})
+	viper.SetDefault("server.tls.min_version", "1.2")
+	viper.SetDefault("server.tls.reload_interval", time.Minute)
+	viper.SetDefault("database.max_open_conns", 25)
+	viper.SetDefault("database.max_idle_conns", 5)
+	viper.SetDefault("database.conn_max_lifetime", 30*time.Minute)
+	viper.SetDefault("database.slow_query_threshold", 200*time.Millisecond)
+	viper.SetDefault("jobs.jitter", 30*time.Second)
+	viper.SetDefault("jobs.otp_cleanup_interval", 15*time.Minute)
+	viper.SetDefault("jobs.idempotency_cleanup_interval", time.Hour)
+	viper.SetDefault("phone.default_region", "IN")
+	viper.SetDefault("otp.default_locale", "en")
+	viper.SetDefault("otp.resend_cooldown", 30*time.Second)
+	viper.SetDefault("otp.max_resends", 3)
+	viper.SetDefault("log.level", "info")
+	viper.SetDefault("tracing.exporter", "none")
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.service_name", "clarityfin-api")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("idempotency.ttl", 24*time.Hour)
+	viper.SetDefault("idempotency.lease", 30*time.Second)
+	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE"})
+	viper.SetDefault("cors.allowed_headers", []string{"Authorization", "Content-Type", "Idempotency-Key", "If-Match", "If-None-Match", "X-Request-ID"})
+	viper.SetDefault("cors.exposed_headers", []string{"ETag", "X-Request-ID", "Idempotent-Replayed", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"})
+	viper.SetDefault("cors.max_age", 10*time.Minute)
+	viper.SetDefault("security.hsts_max_age", 365*24*time.Hour)
+	viper.SetDefault("security.hsts_include_subdomains", true)
+	viper.SetDefault("security.frame_options", "DENY")
+	viper.SetDefault("security.referrer_policy", "no-referrer")
+	viper.SetDefault("security.strict_json", true)
+
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()
@@ -52,6 +277,19 @@ func LoadConfig() (config Config, err error) {
 		return config, err
 	}
 
+	// Profile overrides, e.g. config.prod.yaml
+	viper.SetConfigName("config." + viper.GetString("env"))
+	if err := viper.MergeInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return config, err
+		}
+	}
+
+	if err := bindEnv(viper.GetViper()); err != nil {
+		return config, err
+	}
+
 	err = viper.Unmarshal(&config)
 	return config, err
 }
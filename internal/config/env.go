@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix prefixes every environment variable that overrides a setting,
+// e.g. CLARITYFIN_JWT_SECRET for jwt.secret
+const EnvPrefix = "CLARITYFIN"
+
+// envKeyReplacer maps nested keys to environment variable names
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
+// configKeys lists the viper key of every setting in t, e.g.
+// "server.tls.cert_file". Map-valued settings such as rate_limit.routes are
+// expanded to the entries present in v, e.g.
+// "rate_limit.routes.auth.requests", so existing entries can be overridden;
+// new entries can only be added in the YAML files.
+func configKeys(v *viper.Viper, t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		key := prefix + name
+
+		switch {
+		case field.Type.Kind() == reflect.Struct && field.Type.PkgPath() == t.PkgPath():
+			keys = append(keys, configKeys(v, field.Type, key+".")...)
+		case field.Type.Kind() == reflect.Map:
+			keys = append(keys, mapEntryKeys(v, field.Type.Elem(), key)...)
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// mapEntryKeys lists the keys of the entries of the map setting key that
+// are present in v. elem is the map's value type.
+func mapEntryKeys(v *viper.Viper, elem reflect.Type, key string) []string {
+	var keys []string
+	for _, name := range slices.Sorted(maps.Keys(v.GetStringMap(key))) {
+		entry := key + "." + name
+		switch elem.Kind() {
+		case reflect.Struct:
+			keys = append(keys, configKeys(v, elem, entry+".")...)
+		case reflect.Map:
+			keys = append(keys, mapEntryKeys(v, elem.Elem(), entry)...)
+		default:
+			keys = append(keys, entry)
+		}
+	}
+	return keys
+}
+
+// envName returns the environment variable that overrides key
+func envName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// bindEnv lets CLARITYFIN_<KEY> override every setting and loads
+// CLARITYFIN_<KEY>_FILE, when set, from the named file. Secret files, such
+// as Docker and Kubernetes secrets, keep credentials out of the
+// environment. Trailing newlines are trimmed.
+func bindEnv(v *viper.Viper) error {
+	for _, key := range configKeys(v, reflect.TypeOf(Config{}), "") {
+		if err := v.BindEnv(key); err != nil {
+			return err
+		}
+
+		path, ok := os.LookupEnv(envName(key) + "_FILE")
+		if !ok {
+			continue
+		}
+		if _, set := os.LookupEnv(envName(key)); set {
+			return fmt.Errorf("both %s and %s_FILE are set", envName(key), envName(key))
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s_FILE: %w", envName(key), err)
+		}
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Environment profiles. Staging and production refuse to start with
+// development-grade settings such as a weak JWT secret.
+const (
+	EnvDev     = "dev"
+	EnvStaging = "staging"
+	EnvProd    = "prod"
+)
+
+// minJWTSecretLength is the shortest JWT secret accepted outside dev; HS256
+// keys should carry at least 256 bits
+const minJWTSecretLength = 32
+
+// placeholderPrefix marks the sample secrets, credentials and sender numbers
+// in config.yaml, which are refused outside dev. Matching the prefix rather
+// than the values keeps the check working when the samples change.
+const placeholderPrefix = "your_"
+
+// Validate reports every invalid setting at once, one per line. Checks that
+// only matter for deployed environments apply to staging and prod.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+		}
+	}
+
+	check(slices.Contains([]string{EnvDev, EnvStaging, EnvProd}, c.Env), "env",
+		"must be %q, %q or %q, got %q", EnvDev, EnvStaging, EnvProd, c.Env)
+	deployed := c.Env == EnvStaging || c.Env == EnvProd
+
+	check(c.Server.Port != "", "server.port", "is required")
+	check(c.Server.TLS.CertFile == "" || c.Server.TLS.KeyFile != "", "server.tls.key_file", "is required with cert_file")
+	check(c.Server.TLS.KeyFile == "" || c.Server.TLS.CertFile != "", "server.tls.cert_file", "is required with key_file")
+	check(slices.Contains([]string{"1.2", "1.3"}, c.Server.TLS.MinVersion), "server.tls.min_version",
+		"must be \"1.2\" or \"1.3\", got %q", c.Server.TLS.MinVersion)
+	for i, name := range c.Server.TLS.CipherSuites {
+		check(slices.ContainsFunc(tls.CipherSuites(), func(s *tls.CipherSuite) bool { return s.Name == name }),
+			fmt.Sprintf("server.tls.cipher_suites[%d]", i), "%q is not a known secure cipher suite", name)
+	}
+	check(c.Server.Admin.ClientCAFile == "" || c.Server.TLS.Enabled(), "server.admin.client_ca_file", "requires server.tls")
+
+	check(c.Database.Driver == "postgres" || c.Database.Driver == "sqlite", "database.driver",
+		"must be \"postgres\" or \"sqlite\", got %q", c.Database.Driver)
+	check(c.Database.DSN != "", "database.dsn", "is required")
+
+	switch {
+	case c.JWT.Secret == "":
+		check(false, "jwt.secret", "is required (set %s or %s_FILE)", envName("jwt.secret"), envName("jwt.secret"))
+	case deployed && strings.HasPrefix(c.JWT.Secret, placeholderPrefix):
+		check(false, "jwt.secret", "is the placeholder from config.yaml; generate a new one")
+	case deployed && len(c.JWT.Secret) < minJWTSecretLength:
+		check(false, "jwt.secret", "must be at least %d characters in %s", minJWTSecretLength, c.Env)
+	}
+
+	if err := c.SMS.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("sms: %w", err))
+	} else if deployed {
+		check(c.SMS.Provider != "console" && c.SMS.Provider != "", "sms.provider", "must be a real provider in %s", c.Env)
+		for _, setting := range []struct{ key, value string }{
+			{"sms.twilio.account_sid", c.SMS.Twilio.AccountSID},
+			{"sms.twilio.auth_token", c.SMS.Twilio.AuthToken},
+			{"sms.twilio.from_number", c.SMS.Twilio.FromNumber},
+			{"sms.twilio.whatsapp_from", c.SMS.Twilio.WhatsAppFrom},
+			{"sms.msg91.api_key", c.SMS.MSG91.APIKey},
+		} {
+			if strings.HasPrefix(setting.key, "sms."+c.SMS.Provider+".") {
+				check(!strings.HasPrefix(setting.value, placeholderPrefix), setting.key, "is the placeholder from config.yaml")
+			}
+		}
+	}
+
+	check(c.OTP.ResendCooldown >= 0, "otp.resend_cooldown", "must not be negative")
+	check(c.OTP.MaxResends >= 0, "otp.max_resends", "must not be negative")
+
+	check(slices.Contains([]string{"debug", "info", "warn", "error"}, c.Log.Level), "log.level",
+		"must be debug, info, warn or error, got %q", c.Log.Level)
+	check(slices.Contains([]string{"none", "stdout", "otlp"}, c.Tracing.Exporter), "tracing.exporter",
+		"must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio",
+		"must be between 0 and 1")
+
+	check(c.Idempotency.Lease > 0, "idempotency.lease", "must be positive")
+	check(c.Server.RequestTimeout <= 0 || c.Idempotency.Lease > c.Server.RequestTimeout, "idempotency.lease",
+		"must exceed server.request_timeout (%s)", c.Server.RequestTimeout)
+
+	errs = append(errs, c.RateLimit.Global.validate("rate_limit.global")...)
+	for _, group := range slices.Sorted(maps.Keys(c.RateLimit.Routes)) {
+		key := "rate_limit.routes." + group
+		check(slices.Contains(RateLimitGroups, group), key, "is not a route group (expected one of %s)", strings.Join(RateLimitGroups, ", "))
+		errs = append(errs, c.RateLimit.Routes[group].validate(key)...)
+	}
+	for i, digest := range c.RateLimit.APIKeys {
+		check(isSHA256Hex(digest), fmt.Sprintf("rate_limit.api_keys[%d]", i), "must be a lower-case hex SHA-256 digest")
+	}
+
+	return errors.Join(errs...)
+}
+
+// RateLimitGroups are the route groups rate_limit.routes can configure
+var RateLimitGroups = []string{"auth", "otp", "subscriptions"}
+
+// validate reports the problems with a rate limit rule configured at key
+func (r RateLimitRule) validate(key string) []error {
+	var errs []error
+	if !slices.Contains([]string{"", "ip", "user", "api_key"}, r.Key) {
+		errs = append(errs, fmt.Errorf("%s.key: must be ip, user or api_key, got %q", key, r.Key))
+	}
+	if r.Requests < 0 {
+		errs = append(errs, fmt.Errorf("%s.requests: must not be negative", key))
+	}
+	if r.Requests > 0 && r.Period <= 0 {
+		errs = append(errs, fmt.Errorf("%s.period: must be positive when requests is set", key))
+	}
+	if r.Burst < 0 {
+		errs = append(errs, fmt.Errorf("%s.burst: must not be negative", key))
+	}
+	return errs
+}
+
+// Validate reports whether the configured SMS provider is known and has the
+// credentials it needs
+func (c SMSConfig) Validate() error {
+	var missing []string
+	switch c.Provider {
+	case "twilio":
+		if c.Twilio.AccountSID == "" {
+			missing = append(missing, "sms.twilio.account_sid")
+		}
+		if c.Twilio.AuthToken == "" {
+			missing = append(missing, "sms.twilio.auth_token")
+		}
+		if c.Twilio.FromNumber == "" {
+			missing = append(missing, "sms.twilio.from_number")
+		}
+	case "msg91":
+		if c.MSG91.APIKey == "" {
+			missing = append(missing, "sms.msg91.api_key")
+		}
+	case "console", "":
+		return nil
+	default:
+		return fmt.Errorf("unknown SMS provider %q", c.Provider)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s provider is missing %s", c.Provider, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// isSHA256Hex reports whether s is a lower-case hex SHA-256 digest
+func isSHA256Hex(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,43 +1,132 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"strings"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/hardiksharma/clarityfin-api/internal/config"
-	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+// Supported values for config.DatabaseConfig.Driver
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Store is an open database handle. Repositories are built from Store.DB and
+// the store must be closed when the application shuts down.
+type Store struct {
+	DB *gorm.DB
+}
+
+// memoryStoreSeq makes every in-memory store name unique
+var memoryStoreSeq atomic.Uint64
+
+// Connect opens the database using the configured driver and applies the
+// connection pool settings. Failed and slow queries are logged to log.
+func Connect(config config.DatabaseConfig, log *slog.Logger) (*Store, error) {
+	dialector, err := newDialector(config, log)
+	if err != nil {
+		return nil, err
+	}
 
-// Connect initializes the database connection.
-func Connect(config config.DatabaseConfig) {
-	var err error
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: newGormLogger(log, config.SlowQueryThreshold),
+		// Report unique and foreign key violations as gorm.ErrDuplicatedKey
+		// and gorm.ErrForeignKeyViolated regardless of the driver
+		TranslateError: true,
+		// Store timestamps in UTC. SQLite compares them as text, so rows
+		// written with different offsets would sort and filter wrongly.
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Determine database type based on DSN
-	if strings.Contains(config.DSN, "postgres") || strings.Contains(config.DSN, "localhost") {
-		// PostgreSQL connection
-		DB, err = gorm.Open(postgres.Open(config.DSN), &gorm.Config{})
-	} else {
-		// SQLite connection
-		DB, err = gorm.Open(sqlite.Open(config.DSN), &gorm.Config{})
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
 	}
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+
+	return &Store{DB: db}, nil
+}
 
+// NewInMemory opens an isolated in-memory SQLite store with all migrations
+// applied. Each call gets its own database, which makes it suitable for tests.
+func NewInMemory() (*Store, error) {
+	dsn := fmt.Sprintf("file:clarityfin-mem-%d-%d?mode=memory&cache=shared&_foreign_keys=1",
+		time.Now().UnixNano(), memoryStoreSeq.Add(1))
+
+	store, err := Connect(config.DatabaseConfig{
+		Driver:       DriverSQLite,
+		DSN:          dsn,
+		MaxOpenConns: 1,
+		MaxIdleConns: 1, // the database is dropped once its last connection closes
+	}, slog.Default())
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return nil, err
+	}
+
+	if _, err := MigrateUp(store.DB); err != nil {
+		store.Close()
+		return nil, err
 	}
 
-	fmt.Println("Database connection successfully opened")
+	return store, nil
+}
+
+// Ping checks that the database is reachable
+func (s *Store) Ping(ctx context.Context) error {
+	sqlDB, err := s.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
 
-	// AutoMigrate will create the tables based on your GORM models
-	err = DB.AutoMigrate(&domain.User{}, &domain.Subscription{}, &domain.OTP{}, &domain.Account{}, &domain.Transaction{})
+// Close closes the underlying connection pool
+func (s *Store) Close() error {
+	sqlDB, err := s.DB.DB()
 	if err != nil {
-		log.Fatal("Failed to migrate database:", err)
+		return err
 	}
+	return sqlDB.Close()
+}
 
-	fmt.Println("Database migrated")
+// newDialector builds the GORM dialector for the configured driver
+func newDialector(config config.DatabaseConfig, log *slog.Logger) (gorm.Dialector, error) {
+	switch config.Driver {
+	case DriverPostgres:
+		connConfig, err := pgx.ParseConfig(config.DSN)
+		if err != nil {
+			return nil, err
+		}
+		// Enforced server-side so runaway queries are cancelled by Postgres
+		if config.StatementTimeout > 0 {
+			connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(config.StatementTimeout.Milliseconds(), 10)
+		}
+		return postgres.New(postgres.Config{Conn: stdlib.OpenDB(*connConfig)}), nil
+
+	case DriverSQLite:
+		if config.StatementTimeout > 0 {
+			log.Warn("database.statement_timeout is not supported by the sqlite driver and is ignored")
+		}
+		return sqlite.Open(config.DSN), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q (expected %q or %q)", config.Driver, DriverPostgres, DriverSQLite)
+	}
 }
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger adapts GORM's logger to slog. Queries are logged without their
+// bound parameters so phone numbers and password hashes never reach the logs.
+type gormLogger struct {
+	log           *slog.Logger
+	slowThreshold time.Duration
+}
+
+func newGormLogger(log *slog.Logger, slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{log: log, slowThreshold: slowThreshold}
+}
+
+func (l *gormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.log.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.log.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.log.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+// Trace logs failed queries and queries slower than the threshold
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, context.Canceled):
+		sql, rows := fc()
+		l.log.ErrorContext(ctx, "query failed",
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+			slog.String("error", err.Error()),
+		)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		sql, rows := fc()
+		l.log.WarnContext(ctx, "slow query",
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+		)
+	}
+}
+
+// ParamsFilter drops bound parameters so logged SQL keeps its placeholders
+func (l *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
@@ -0,0 +1,63 @@
+-- Baseline schema. IF NOT EXISTS lets databases created by the old
+-- AutoMigrate start tracking migrations without changes.
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial PRIMARY KEY,
+    "phone_number" text NOT NULL,
+    "password" text NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    CONSTRAINT "uni_users_phone_number" UNIQUE ("phone_number")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "subscriptions" (
+    "id" bigserial PRIMARY KEY,
+    "name" text NOT NULL,
+    "amount" decimal NOT NULL,
+    "user_id" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    CONSTRAINT "fk_subscriptions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_subscriptions_deleted_at" ON "subscriptions" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "otps" (
+    "id" bigserial PRIMARY KEY,
+    "phone_number" text NOT NULL,
+    "code" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "is_used" boolean DEFAULT false,
+    "created_at" timestamptz,
+    "updated_at" timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS "accounts" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL,
+    "account_type" text NOT NULL,
+    "balance" decimal DEFAULT 0,
+    "currency" text DEFAULT 'USD',
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    CONSTRAINT "fk_accounts_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_accounts_deleted_at" ON "accounts" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "transactions" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "type" text NOT NULL,
+    "amount" decimal NOT NULL,
+    "description" text,
+    "category" text,
+    "status" text DEFAULT 'completed',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    CONSTRAINT "fk_transactions_account" FOREIGN KEY ("account_id") REFERENCES "accounts"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_transactions_deleted_at" ON "transactions" ("deleted_at");
@@ -0,0 +1,3 @@
+ALTER TABLE "users" DROP COLUMN IF EXISTS "language";
+ALTER TABLE "otps" DROP COLUMN IF EXISTS "purpose";
+ALTER TABLE "otps" DROP COLUMN IF EXISTS "channel";
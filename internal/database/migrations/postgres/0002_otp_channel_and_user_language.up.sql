@@ -0,0 +1,3 @@
+ALTER TABLE "otps" ADD COLUMN IF NOT EXISTS "channel" text NOT NULL DEFAULT 'sms';
+ALTER TABLE "otps" ADD COLUMN IF NOT EXISTS "purpose" text NOT NULL DEFAULT 'verification';
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "language" text;
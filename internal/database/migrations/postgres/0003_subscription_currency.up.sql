@@ -0,0 +1 @@
+ALTER TABLE "subscriptions" ADD COLUMN IF NOT EXISTS "currency" char(3) NOT NULL DEFAULT 'INR';
@@ -0,0 +1,3 @@
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "subscriptions" DROP COLUMN IF EXISTS "version";
@@ -0,0 +1,4 @@
+-- Row versions for optimistic concurrency control
+ALTER TABLE "subscriptions" ADD COLUMN IF NOT EXISTS "version" integer NOT NULL DEFAULT 1;
+ALTER TABLE "accounts" ADD COLUMN IF NOT EXISTS "version" integer NOT NULL DEFAULT 1;
+ALTER TABLE "transactions" ADD COLUMN IF NOT EXISTS "version" integer NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+-- Lease for in-flight reservations and replayed response headers
+ALTER TABLE "idempotency_keys" ADD COLUMN IF NOT EXISTS "locked_until" timestamptz;
+ALTER TABLE "idempotency_keys" ADD COLUMN IF NOT EXISTS "etag" text;
+ALTER TABLE "idempotency_keys" ADD COLUMN IF NOT EXISTS "location" text;
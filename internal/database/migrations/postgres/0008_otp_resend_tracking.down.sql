@@ -0,0 +1,2 @@
+ALTER TABLE "otps" DROP COLUMN IF EXISTS "last_sent_at";
+ALTER TABLE "otps" DROP COLUMN IF EXISTS "resend_count";
@@ -0,0 +1,3 @@
+-- Resend cooldown and cap per OTP
+ALTER TABLE "otps" ADD COLUMN IF NOT EXISTS "resend_count" integer NOT NULL DEFAULT 0;
+ALTER TABLE "otps" ADD COLUMN IF NOT EXISTS "last_sent_at" timestamptz;
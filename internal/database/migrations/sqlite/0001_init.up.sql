@@ -0,0 +1,63 @@
+-- Baseline schema. IF NOT EXISTS lets databases created by the old
+-- AutoMigrate start tracking migrations without changes.
+CREATE TABLE IF NOT EXISTS `users` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `phone_number` text NOT NULL,
+    `password` text NOT NULL,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    CONSTRAINT `uni_users_phone_number` UNIQUE (`phone_number`)
+);
+CREATE INDEX IF NOT EXISTS `idx_users_deleted_at` ON `users`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `subscriptions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `amount` real NOT NULL,
+    `user_id` integer NOT NULL,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    CONSTRAINT `fk_subscriptions_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_subscriptions_deleted_at` ON `subscriptions`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `otps` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `phone_number` text NOT NULL,
+    `code` text NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `is_used` numeric DEFAULT false,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+
+CREATE TABLE IF NOT EXISTS `accounts` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `account_type` text NOT NULL,
+    `balance` real DEFAULT 0,
+    `currency` text DEFAULT 'USD',
+    `is_active` numeric DEFAULT true,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    CONSTRAINT `fk_accounts_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_accounts_deleted_at` ON `accounts`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `transactions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `account_id` integer NOT NULL,
+    `type` text NOT NULL,
+    `amount` real NOT NULL,
+    `description` text,
+    `category` text,
+    `status` text DEFAULT 'completed',
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    CONSTRAINT `fk_transactions_account` FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_transactions_deleted_at` ON `transactions`(`deleted_at`);
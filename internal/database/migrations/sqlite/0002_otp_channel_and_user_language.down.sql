@@ -0,0 +1,3 @@
+ALTER TABLE `users` DROP COLUMN `language`;
+ALTER TABLE `otps` DROP COLUMN `purpose`;
+ALTER TABLE `otps` DROP COLUMN `channel`;
@@ -0,0 +1,3 @@
+ALTER TABLE `otps` ADD COLUMN `channel` text NOT NULL DEFAULT 'sms';
+ALTER TABLE `otps` ADD COLUMN `purpose` text NOT NULL DEFAULT 'verification';
+ALTER TABLE `users` ADD COLUMN `language` text;
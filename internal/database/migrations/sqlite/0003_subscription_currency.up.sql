@@ -0,0 +1 @@
+ALTER TABLE `subscriptions` ADD COLUMN `currency` text NOT NULL DEFAULT 'INR';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_subscriptions_user_created`;
+ALTER TABLE `subscriptions` DROP COLUMN `category`;
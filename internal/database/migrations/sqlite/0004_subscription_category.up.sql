@@ -0,0 +1,2 @@
+ALTER TABLE `subscriptions` ADD COLUMN `category` text;
+CREATE INDEX IF NOT EXISTS `idx_subscriptions_user_created` ON `subscriptions`(`user_id`, `created_at`);
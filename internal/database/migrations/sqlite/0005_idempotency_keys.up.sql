@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `idempotency_keys` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `owner` text NOT NULL,
+    `key` text NOT NULL,
+    `fingerprint` text NOT NULL,
+    `status_code` integer,
+    `content_type` text,
+    `body` blob,
+    `expires_at` datetime NOT NULL,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_idempotency_keys_owner_key` ON `idempotency_keys`(`owner`, `key`);
+CREATE INDEX IF NOT EXISTS `idx_idempotency_keys_expires_at` ON `idempotency_keys`(`expires_at`);
@@ -0,0 +1,4 @@
+-- Lease for in-flight reservations and replayed response headers
+ALTER TABLE `idempotency_keys` ADD COLUMN `locked_until` datetime;
+ALTER TABLE `idempotency_keys` ADD COLUMN `etag` text;
+ALTER TABLE `idempotency_keys` ADD COLUMN `location` text;
@@ -0,0 +1,2 @@
+ALTER TABLE `otps` DROP COLUMN `last_sent_at`;
+ALTER TABLE `otps` DROP COLUMN `resend_count`;
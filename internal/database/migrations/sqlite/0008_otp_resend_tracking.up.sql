@@ -0,0 +1,3 @@
+-- Resend cooldown and cap per OTP
+ALTER TABLE `otps` ADD COLUMN `resend_count` integer NOT NULL DEFAULT 0;
+ALTER TABLE `otps` ADD COLUMN `last_sent_at` datetime;
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/hardiksharma/clarityfin-api/pkg/phone"
+	"gorm.io/gorm"
+)
+
+// PhoneCollision describes users whose phone numbers normalize to the same
+// E.164 number. These rows are left untouched and need manual resolution.
+type PhoneCollision struct {
+	Normalized string
+	UserIDs    []uint
+	Raw        []string
+}
+
+// PhoneMigrationReport summarizes a phone number normalization run
+type PhoneMigrationReport struct {
+	UsersUpdated int
+	OTPsUpdated  int
+	Invalid      map[uint]string // user ID -> raw phone number that failed to parse
+	Collisions   []PhoneCollision
+}
+
+// NormalizePhoneNumbers rewrites users.phone_number and otps.phone_number to
+// E.164 using the configured default region. Users that would collide with
+// another user after normalization are reported and not modified. When dryRun
+// is set the report is computed but nothing is written.
+func NormalizePhoneNumbers(db *gorm.DB, dryRun bool) (*PhoneMigrationReport, error) {
+	report := &PhoneMigrationReport{Invalid: make(map[uint]string)}
+
+	var users []domain.User
+	if err := db.Select("id", "phone_number").Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	// Group users by their normalized number so collisions can be detected
+	groups := make(map[string][]domain.User)
+	var order []string
+	for _, user := range users {
+		normalized, err := phone.Normalize(user.PhoneNumber)
+		if err != nil {
+			report.Invalid[user.ID] = user.PhoneNumber
+			continue
+		}
+		if _, ok := groups[normalized]; !ok {
+			order = append(order, normalized)
+		}
+		groups[normalized] = append(groups[normalized], user)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, normalized := range order {
+			group := groups[normalized]
+			if len(group) > 1 {
+				collision := PhoneCollision{Normalized: normalized}
+				for _, user := range group {
+					collision.UserIDs = append(collision.UserIDs, user.ID)
+					collision.Raw = append(collision.Raw, user.PhoneNumber)
+				}
+				report.Collisions = append(report.Collisions, collision)
+				continue
+			}
+
+			user := group[0]
+			if user.PhoneNumber == normalized {
+				continue
+			}
+			report.UsersUpdated++
+			if dryRun {
+				continue
+			}
+			if err := tx.Model(&domain.User{}).Where("id = ?", user.ID).
+				Update("phone_number", normalized).Error; err != nil {
+				return err
+			}
+		}
+
+		var otps []domain.OTP
+		if err := tx.Select("id", "phone_number").Find(&otps).Error; err != nil {
+			return err
+		}
+		for _, otp := range otps {
+			normalized, err := phone.Normalize(otp.PhoneNumber)
+			if err != nil || normalized == otp.PhoneNumber {
+				continue
+			}
+			report.OTPsUpdated++
+			if dryRun {
+				continue
+			}
+			if err := tx.Model(&domain.OTP{}).Where("id = ?", otp.ID).
+				Update("phone_number", normalized).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"time"
 
 	"gorm.io/gorm"
@@ -15,6 +16,7 @@ type Account struct {
 	Balance     float64        `json:"balance" gorm:"default:0"`
 	Currency    string         `json:"currency" gorm:"default:'USD'"`
 	IsActive    bool           `json:"is_active" gorm:"default:true"`
+	Version     uint           `json:"version" gorm:"not null;default:1"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -22,24 +24,24 @@ type Account struct {
 
 // AccountRepository defines the interface for account data operations
 type AccountRepository interface {
-	Create(account *Account) error
-	FindByID(id uint) (*Account, error)
-	FindByUserID(userID uint) ([]*Account, error)
-	Update(account *Account) error
-	Delete(id uint) error
+	Create(ctx context.Context, account *Account) error
+	FindByID(ctx context.Context, id uint) (*Account, error)
+	FindByUserID(ctx context.Context, userID uint) ([]*Account, error)
+	Update(ctx context.Context, account *Account) error
+	Delete(ctx context.Context, id uint) error
 }
 
 // AccountService defines the interface for account business logic
 type AccountService interface {
-	CreateAccount(userID uint, accountType, currency string) (*Account, error)
-	GetUserAccounts(userID uint) ([]*Account, error)
-	GetAccountByID(id uint) (*Account, error)
-	UpdateBalance(accountID uint, amount float64) error
+	CreateAccount(ctx context.Context, userID uint, accountType, currency string) (*Account, error)
+	GetUserAccounts(ctx context.Context, userID uint) ([]*Account, error)
+	GetAccountByID(ctx context.Context, id uint) (*Account, error)
+	UpdateBalance(ctx context.Context, accountID uint, amount float64) error
 }
 
 // AccountUseCase defines the interface for account application logic
 type AccountUseCase interface {
-	CreateAccount(userID uint, accountType, currency string) (*Account, error)
-	GetUserAccounts(userID uint) ([]*Account, error)
-	GetAccountByID(id uint) (*Account, error)
+	CreateAccount(ctx context.Context, userID uint, accountType, currency string) (*Account, error)
+	GetUserAccounts(ctx context.Context, userID uint) ([]*Account, error)
+	GetAccountByID(ctx context.Context, id uint) (*Account, error)
 }
@@ -0,0 +1,133 @@
+package domain
+
+import "errors"
+
+// ErrorKind classifies domain errors so that every transport maps them the
+// same way
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindUnauthorized
+	KindRateLimited
+	KindPreconditionFailed
+	KindPreconditionRequired
+	KindTooLarge
+)
+
+// Error is a classified domain error. Code is a stable, machine-readable
+// identifier such as "user_already_exists" that clients can rely on; Message
+// is for humans and may change.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	// Fields describes which input fields failed validation, if any
+	Fields []FieldError
+	// Err is the underlying cause, if any. It is never shown to clients.
+	Err error
+}
+
+// FieldError describes why a single input field is invalid
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same code, so sentinel
+// errors still match after Wrap or WithFields
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of e that records cause as the underlying error
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.Err = cause
+	return &wrapped
+}
+
+// WithFields returns a copy of e carrying field-level details
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	withFields := *e
+	withFields.Fields = fields
+	return &withFields
+}
+
+// NewValidationError creates an error for input that is malformed or breaks
+// a business rule
+func NewValidationError(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+// NewNotFoundError creates an error for a resource that does not exist
+func NewNotFoundError(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+// NewConflictError creates an error for a request that conflicts with the
+// current state, e.g. a duplicate
+func NewConflictError(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// NewUnauthorizedError creates an error for missing or invalid credentials
+func NewUnauthorizedError(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+// NewRateLimitedError creates an error for a caller that exceeded a limit
+func NewRateLimitedError(code, message string) *Error {
+	return &Error{Kind: KindRateLimited, Code: code, Message: message}
+}
+
+// NewPreconditionFailedError creates an error for a conditional request
+// whose precondition, such as an If-Match version, no longer holds
+func NewPreconditionFailedError(code, message string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Code: code, Message: message}
+}
+
+// NewPreconditionRequiredError creates an error for a request that must be
+// conditional but is not
+func NewPreconditionRequiredError(code, message string) *Error {
+	return &Error{Kind: KindPreconditionRequired, Code: code, Message: message}
+}
+
+// NewTooLargeError creates an error for input that exceeds a size limit
+func NewTooLargeError(code, message string) *Error {
+	return &Error{Kind: KindTooLarge, Code: code, Message: message}
+}
+
+// ErrVersionConflict is returned when a write names a version of a resource
+// that has since been changed by another request
+var ErrVersionConflict = NewPreconditionFailedError("version_conflict", "the resource was modified by another request")
+
+// KindOf returns the kind of the first *Error in err's chain, or
+// KindInternal if there is none
+func KindOf(err error) ErrorKind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	return KindInternal
+}
+
+// ErrInvalidPhoneNumber is returned when a phone number cannot be parsed
+var ErrInvalidPhoneNumber = NewValidationError("invalid_phone_number", "invalid phone number",
+	FieldError{Field: "phone_number", Code: "phone", Message: "must be a valid phone number"})
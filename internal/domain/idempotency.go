@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyRecord remembers the outcome of a request sent with an
+// Idempotency-Key header so a retry can be answered with the same response.
+// A record with a zero StatusCode is still being processed; once LockedUntil
+// has passed, the request is presumed lost and another may take the key over.
+type IdempotencyRecord struct {
+	ID          uint   `gorm:"primaryKey"`
+	Owner       string `gorm:"not null"` // the authenticated user the key belongs to
+	Key         string `gorm:"not null"`
+	Fingerprint string `gorm:"not null"` // hash of the method, path and body
+	LockedUntil time.Time
+	StatusCode  int
+	ContentType string
+	ETag        string `gorm:"column:etag"`
+	Location    string
+	Body        []byte
+	ExpiresAt   time.Time `gorm:"not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName keeps the table name short
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_keys"
+}
+
+// Completed reports whether the original request has finished and its
+// response can be replayed
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// IdempotencyRepository defines the interface for idempotency key storage
+type IdempotencyRepository interface {
+	// Reserve stores record unless the owner already holds an unexpired
+	// record for the key that is completed or still within its lease, in
+	// which case that record is returned instead
+	Reserve(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, error)
+	// Complete stores the response fields of record
+	Complete(ctx context.Context, record *IdempotencyRecord) error
+	Release(ctx context.Context, id uint) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -1,37 +1,114 @@
 package domain
 
 import (
+	"context"
 	"time"
 )
 
+// OTPChannel is the medium used to deliver an OTP code
+type OTPChannel string
+
+const (
+	OTPChannelSMS      OTPChannel = "sms"
+	OTPChannelVoice    OTPChannel = "voice"
+	OTPChannelWhatsApp OTPChannel = "whatsapp"
+)
+
+// OTPPurpose identifies why an OTP was requested and selects its message template
+type OTPPurpose string
+
+const (
+	OTPPurposeVerification OTPPurpose = "verification"
+	OTPPurposeRegistration OTPPurpose = "registration"
+)
+
+// OTPDelivery describes how an OTP should be delivered
+type OTPDelivery struct {
+	Channel OTPChannel
+	Purpose OTPPurpose
+	// Locale is a language tag or Accept-Language header value. It is used
+	// when the recipient has no stored language preference.
+	Locale string
+}
+
+// ErrOTPChannelUnsupported is returned when the configured provider cannot
+// deliver OTPs over the requested channel
+var ErrOTPChannelUnsupported = NewValidationError("otp_channel_unsupported", "OTP channel not supported",
+	FieldError{Field: "channel", Code: "unsupported", Message: "is not supported by the configured provider"})
+
+// ErrInvalidOTP is returned when an OTP does not exist, has expired or was
+// already used
+var ErrInvalidOTP = NewValidationError("invalid_otp", "invalid OTP")
+
+// ErrNoActiveOTP is returned when a resend is requested but there is no
+// unexpired, unused OTP for the phone number
+var ErrNoActiveOTP = NewNotFoundError("no_active_otp", "no active OTP for this phone number")
+
+// ErrOTPResendTooSoon is returned when a resend comes before the cooldown
+// since the last delivery has passed
+var ErrOTPResendTooSoon = NewRateLimitedError("otp_resend_too_soon", "OTP was sent recently, try again later")
+
+// ErrOTPResendLimitReached is returned when an OTP has been resent the
+// maximum number of times
+var ErrOTPResendLimitReached = NewRateLimitedError("otp_resend_limit_reached", "OTP resend limit reached, request a new code")
+
+// ErrOTPSendTooSoon is returned when a new code is requested before the
+// cooldown since the last delivery to the phone number has passed
+var ErrOTPSendTooSoon = NewRateLimitedError("otp_send_too_soon", "an OTP was sent recently, try again later")
+
+// ErrOTPSendLimitReached is returned when a new code is requested while the
+// active one has used up its deliveries
+var ErrOTPSendLimitReached = NewRateLimitedError("otp_send_limit_reached", "too many OTPs requested for this phone number, try again later")
+
 // OTP represents the OTP domain entity
 type OTP struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	PhoneNumber string    `json:"phone_number" gorm:"not null"`
-	Code        string    `json:"code" gorm:"not null"`
-	ExpiresAt   time.Time `json:"expires_at" gorm:"not null"`
-	IsUsed      bool      `json:"is_used" gorm:"default:false"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	PhoneNumber string     `json:"phone_number" gorm:"not null"`
+	Code        string     `json:"code" gorm:"not null"`
+	Channel     OTPChannel `json:"channel" gorm:"not null;default:'sms'"`
+	Purpose     OTPPurpose `json:"purpose" gorm:"not null;default:'verification'"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	IsUsed      bool       `json:"is_used" gorm:"default:false"`
+	// ResendCount counts deliveries after the first to the phone number
+	// while a code is active, by resend or by replacing the code
+	ResendCount int        `json:"resend_count" gorm:"not null;default:0"`
+	LastSentAt  *time.Time `json:"last_sent_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 // OTPRepository defines the interface for OTP data operations
 type OTPRepository interface {
-	Create(otp *OTP) error
-	FindByPhoneNumberAndCode(phoneNumber, code string) (*OTP, error)
-	MarkAsUsed(id uint) error
-	DeleteExpired() error
+	Create(ctx context.Context, otp *OTP) error
+	FindLatestActive(ctx context.Context, phoneNumber string) (*OTP, error)
+	// ClaimResend records a resend over channel unless the OTP was sent
+	// within cooldown or has been resent maxResends times, and reports
+	// whether it did
+	ClaimResend(ctx context.Context, id uint, channel OTPChannel, cooldown time.Duration, maxResends int) (bool, error)
+	// Supersede marks the OTP used so a new code can replace it, under the
+	// same limits as ClaimResend, and reports whether it did
+	Supersede(ctx context.Context, id uint, cooldown time.Duration, maxResends int) (bool, error)
+	// Consume marks a valid OTP issued for purpose as used
+	Consume(ctx context.Context, phoneNumber, code string, purpose OTPPurpose) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// OTPSender delivers a rendered OTP message to a phone number over a single channel
+type OTPSender interface {
+	Send(ctx context.Context, phoneNumber, body string) error
 }
 
 // OTPService defines the interface for OTP business logic
 type OTPService interface {
-	GenerateOTP(phoneNumber string) error
-	VerifyOTP(phoneNumber, code string) (bool, error)
-	SendOTP(phoneNumber, code string) error
+	GenerateOTP(ctx context.Context, phoneNumber string, delivery OTPDelivery) error
+	ResendOTP(ctx context.Context, phoneNumber string, delivery OTPDelivery) error
+	VerifyOTP(ctx context.Context, phoneNumber, code string) (bool, error)
+	SendOTP(ctx context.Context, phoneNumber, code string, delivery OTPDelivery) error
 }
 
 // OTPUseCase defines the interface for OTP application logic
 type OTPUseCase interface {
-	SendOTP(phoneNumber string) error
-	VerifyOTP(phoneNumber, code string) (bool, error)
+	SendOTP(ctx context.Context, phoneNumber string, delivery OTPDelivery) error
+	ResendOTP(ctx context.Context, phoneNumber string, delivery OTPDelivery) error
+	VerifyOTP(ctx context.Context, phoneNumber, code string) (bool, error)
 }
@@ -1,46 +1,83 @@
 package domain
 
 import (
+	"context"
 	"time"
 
+	"github.com/hardiksharma/clarityfin-api/pkg/pagination"
 	"gorm.io/gorm"
 )
 
+// ErrSubscriptionNotFound is returned when a subscription does not exist
+var ErrSubscriptionNotFound = NewNotFoundError("subscription_not_found", "subscription not found")
+
+// DefaultCurrency is the ISO 4217 currency used when none is given
+const DefaultCurrency = "INR"
+
 // Subscription represents the subscription domain entity
 type Subscription struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	Name      string         `json:"name" gorm:"not null"`
 	Amount    float64        `json:"amount" gorm:"not null"`
+	Currency  string         `json:"currency" gorm:"not null;default:'INR'"`
+	Category  string         `json:"category,omitempty"`
 	UserID    uint           `json:"user_id" gorm:"not null"`
 	User      *User          `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Version   uint           `json:"version" gorm:"not null;default:1"` // incremented on every update
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// SubscriptionSortFields are the fields subscriptions can be sorted by
+var SubscriptionSortFields = []string{"name", "amount", "created_at"}
+
+// DefaultSubscriptionSort lists the newest subscriptions first
+var DefaultSubscriptionSort = pagination.Sort{Field: "created_at", Desc: true}
+
+// SubscriptionFilter narrows a subscription listing. Zero values and nil
+// pointers do not filter.
+type SubscriptionFilter struct {
+	Name          string // case-insensitive substring of the name
+	MinAmount     *float64
+	MaxAmount     *float64
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Category      string
+}
+
+// SubscriptionChanges lists the fields to change in an update. Nil fields
+// are left as they are.
+type SubscriptionChanges struct {
+	Name     *string
+	Amount   *float64
+	Currency *string
+	Category *string
+}
+
 // SubscriptionRepository defines the interface for subscription data operations
 type SubscriptionRepository interface {
-	Create(subscription *Subscription) error
-	FindByID(id uint) (*Subscription, error)
-	FindByUserID(userID uint) ([]*Subscription, error)
-	Update(subscription *Subscription) error
-	Delete(id uint) error
+	Create(ctx context.Context, subscription *Subscription) error
+	FindByID(ctx context.Context, id uint) (*Subscription, error)
+	List(ctx context.Context, userID uint, filter SubscriptionFilter, page pagination.Request) (*pagination.Page[*Subscription], error)
+	Update(ctx context.Context, subscription *Subscription) error
+	Delete(ctx context.Context, id, version uint) error
 }
 
 // SubscriptionService defines the interface for subscription business logic
 type SubscriptionService interface {
-	CreateSubscription(userID uint, name string, amount float64) (*Subscription, error)
-	GetUserSubscriptions(userID uint) ([]*Subscription, error)
-	GetSubscriptionByID(id uint) (*Subscription, error)
-	UpdateSubscription(id uint, name string, amount float64) (*Subscription, error)
-	DeleteSubscription(id uint) error
+	CreateSubscription(ctx context.Context, userID uint, name string, amount float64, currency, category string) (*Subscription, error)
+	ListSubscriptions(ctx context.Context, userID uint, filter SubscriptionFilter, page pagination.Request) (*pagination.Page[*Subscription], error)
+	GetSubscriptionByID(ctx context.Context, userID, id uint) (*Subscription, error)
+	UpdateSubscription(ctx context.Context, userID, id, version uint, changes SubscriptionChanges) (*Subscription, error)
+	DeleteSubscription(ctx context.Context, userID, id, version uint) error
 }
 
 // SubscriptionUseCase defines the interface for subscription application logic
 type SubscriptionUseCase interface {
-	CreateSubscription(userID uint, name string, amount float64) (*Subscription, error)
-	GetUserSubscriptions(userID uint) ([]*Subscription, error)
-	GetSubscriptionByID(id uint) (*Subscription, error)
-	UpdateSubscription(id uint, name string, amount float64) (*Subscription, error)
-	DeleteSubscription(id uint) error
+	CreateSubscription(ctx context.Context, userID uint, name string, amount float64, currency, category string) (*Subscription, error)
+	ListSubscriptions(ctx context.Context, userID uint, filter SubscriptionFilter, page pagination.Request) (*pagination.Page[*Subscription], error)
+	GetSubscriptionByID(ctx context.Context, userID, id uint) (*Subscription, error)
+	UpdateSubscription(ctx context.Context, userID, id, version uint, changes SubscriptionChanges) (*Subscription, error)
+	DeleteSubscription(ctx context.Context, userID, id, version uint) error
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,6 +17,7 @@ type Transaction struct {
 	Description string         `json:"description"`
 	Category    string         `json:"category"`                          // food, transport, entertainment, etc.
 	Status      string         `json:"status" gorm:"default:'completed'"` // pending, completed, failed
+	Version     uint           `json:"version" gorm:"not null;default:1"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -23,26 +25,26 @@ type Transaction struct {
 
 // TransactionRepository defines the interface for transaction data operations
 type TransactionRepository interface {
-	Create(transaction *Transaction) error
-	FindByID(id uint) (*Transaction, error)
-	FindByAccountID(accountID uint) ([]*Transaction, error)
-	FindByUserID(userID uint) ([]*Transaction, error)
-	Update(transaction *Transaction) error
-	Delete(id uint) error
+	Create(ctx context.Context, transaction *Transaction) error
+	FindByID(ctx context.Context, id uint) (*Transaction, error)
+	FindByAccountID(ctx context.Context, accountID uint) ([]*Transaction, error)
+	FindByUserID(ctx context.Context, userID uint) ([]*Transaction, error)
+	Update(ctx context.Context, transaction *Transaction) error
+	Delete(ctx context.Context, id uint) error
 }
 
 // TransactionService defines the interface for transaction business logic
 type TransactionService interface {
-	CreateTransaction(accountID uint, transactionType, description, category string, amount float64) (*Transaction, error)
-	GetAccountTransactions(accountID uint) ([]*Transaction, error)
-	GetUserTransactions(userID uint) ([]*Transaction, error)
-	GetTransactionByID(id uint) (*Transaction, error)
+	CreateTransaction(ctx context.Context, accountID uint, transactionType, description, category string, amount float64) (*Transaction, error)
+	GetAccountTransactions(ctx context.Context, accountID uint) ([]*Transaction, error)
+	GetUserTransactions(ctx context.Context, userID uint) ([]*Transaction, error)
+	GetTransactionByID(ctx context.Context, id uint) (*Transaction, error)
 }
 
 // TransactionUseCase defines the interface for transaction application logic
 type TransactionUseCase interface {
-	CreateTransaction(accountID uint, transactionType, description, category string, amount float64) (*Transaction, error)
-	GetAccountTransactions(accountID uint) ([]*Transaction, error)
-	GetUserTransactions(userID uint) ([]*Transaction, error)
-	GetTransactionByID(id uint) (*Transaction, error)
+	CreateTransaction(ctx context.Context, accountID uint, transactionType, description, category string, amount float64) (*Transaction, error)
+	GetAccountTransactions(ctx context.Context, accountID uint) ([]*Transaction, error)
+	GetUserTransactions(ctx context.Context, userID uint) ([]*Transaction, error)
+	GetTransactionByID(ctx context.Context, id uint) (*Transaction, error)
 }
@@ -0,0 +1,32 @@
+package domain
+
+import "context"
+
+// Repositories groups the repositories bound to a single unit of work
+type Repositories struct {
+	Users         UserRepository
+	Subscriptions SubscriptionRepository
+	OTPs          OTPRepository
+}
+
+// Isolation is the transaction isolation level a unit of work runs at
+type Isolation int
+
+const (
+	// IsolationDefault uses the database default, READ COMMITTED on Postgres.
+	// It suits units of work that rely on unique indexes and conditional
+	// updates for correctness.
+	IsolationDefault Isolation = iota
+	// IsolationSerializable is for read-then-write units of work, such as
+	// posting a transaction and updating the account balance, that must not
+	// act on rows changed concurrently. Conflicts are retried.
+	IsolationSerializable
+)
+
+// UnitOfWork runs operations that span several repositories in one
+// transaction. The transaction commits if fn returns nil and rolls back
+// otherwise. fn may be called more than once if the transaction is retried,
+// so it must not have side effects outside the repositories it is given.
+type UnitOfWork interface {
+	Do(ctx context.Context, isolation Isolation, fn func(ctx context.Context, repos Repositories) error) error
+}
@@ -1,16 +1,32 @@
 package domain
 
 import (
+	"context"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrUserAlreadyExists is returned when registering a phone number that is taken
+var ErrUserAlreadyExists = NewConflictError("user_already_exists", "user already exists")
+
+// ErrPhoneVerificationRequired is returned when registration without an OTP
+// is disabled
+var ErrPhoneVerificationRequired = NewValidationError("phone_verification_required", "phone verification required")
+
+// ErrInvalidCredentials is returned when a phone number and password do not
+// match an account. It does not reveal which of the two was wrong.
+var ErrInvalidCredentials = NewUnauthorizedError("invalid_credentials", "invalid credentials")
+
+// ErrUserNotFound is returned when a user does not exist
+var ErrUserNotFound = NewNotFoundError("user_not_found", "user not found")
+
 // User represents the user domain entity
 type User struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	PhoneNumber string         `json:"phone_number" gorm:"unique;not null"`
-	Password    string         `json:"-" gorm:"not null"` // "-" means this field won't be serialized
+	Password    string         `json:"-" gorm:"not null"`  // "-" means this field won't be serialized
+	Language    string         `json:"language,omitempty"` // preferred BCP 47 language tag, e.g. "hi"
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -18,23 +34,25 @@ type User struct {
 
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
-	Create(user *User) error
-	FindByPhoneNumber(phoneNumber string) (*User, error)
-	FindByID(id uint) (*User, error)
-	Update(user *User) error
-	Delete(id uint) error
+	Create(ctx context.Context, user *User) error
+	FindByPhoneNumber(ctx context.Context, phoneNumber string) (*User, error)
+	FindByID(ctx context.Context, id uint) (*User, error)
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, id uint) error
 }
 
 // UserService defines the interface for user business logic
 type UserService interface {
-	Register(phoneNumber, password string) error
-	Authenticate(phoneNumber, password string) (*User, error)
-	GetByID(id uint) (*User, error)
-	GetByPhoneNumber(phoneNumber string) (*User, error)
+	Register(ctx context.Context, phoneNumber, password, language string) error
+	RegisterWithOTP(ctx context.Context, phoneNumber, password, language, code string) error
+	Authenticate(ctx context.Context, phoneNumber, password string) (*User, error)
+	GetByID(ctx context.Context, id uint) (*User, error)
+	GetByPhoneNumber(ctx context.Context, phoneNumber string) (*User, error)
 }
 
 // UserUseCase defines the interface for user application logic
 type UserUseCase interface {
-	Register(phoneNumber, password string) error
-	Login(phoneNumber, password string) (string, error)
+	Register(ctx context.Context, phoneNumber, password, language string) error
+	RegisterWithOTP(ctx context.Context, phoneNumber, password, language, code string) error
+	Login(ctx context.Context, phoneNumber, password string) (string, error)
 }
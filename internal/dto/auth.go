@@ -2,15 +2,16 @@ package dto
 
 // RegisterRequest represents the request body for user registration
 type RegisterRequest struct {
-	PhoneNumber string `json:"phone_number" binding:"required" validate:"required,min=10,max=15"`
-	Password    string `json:"password" binding:"required" validate:"required,min=6"`
-	OTPCode     string `json:"otp_code,omitempty" validate:"omitempty,len=6"`
+	PhoneNumber string `json:"phone_number" validate:"required,phone"`
+	Password    string `json:"password" validate:"required,min=6"`
+	OTPCode     string `json:"otp_code,omitempty" validate:"omitempty,len=6,numeric"`
+	Language    string `json:"language,omitempty" validate:"omitempty,bcp47_language_tag"`
 }
 
 // LoginRequest represents the request body for user login
 type LoginRequest struct {
-	PhoneNumber string `json:"phone_number" binding:"required" validate:"required"`
-	Password    string `json:"password" binding:"required" validate:"required"`
+	PhoneNumber string `json:"phone_number" validate:"required"`
+	Password    string `json:"password" validate:"required"`
 }
 
 // AuthResponse represents the response for authentication operations
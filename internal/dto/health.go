@@ -0,0 +1,14 @@
+package dto
+
+// HealthCheckResult represents the outcome of a single dependency check
+type HealthCheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"` // "ok" or "fail"
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// HealthResponse represents the response for liveness and readiness probes
+type HealthResponse struct {
+	Status string              `json:"status"` // "ok", "unavailable" or "shutting_down"
+	Checks []HealthCheckResult `json:"checks,omitempty"`
+}
@@ -1,14 +1,24 @@
 package dto
 
-// SendOTPRequest represents the request body for sending OTP
+// SendOTPRequest represents the request body for sending OTP.
+// Channel defaults to "sms" and Purpose to "verification" when omitted.
 type SendOTPRequest struct {
-	PhoneNumber string `json:"phone_number" binding:"required" validate:"required,min=10,max=15"`
+	PhoneNumber string `json:"phone_number" validate:"required,phone"`
+	Channel     string `json:"channel,omitempty" validate:"omitempty,oneof=sms voice whatsapp"`
+	Purpose     string `json:"purpose,omitempty" validate:"omitempty,oneof=verification registration"`
+}
+
+// ResendOTPRequest represents the request body for re-delivering an active
+// OTP, optionally over a different channel
+type ResendOTPRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required,phone"`
+	Channel     string `json:"channel" validate:"required,oneof=sms voice whatsapp"`
 }
 
 // VerifyOTPRequest represents the request body for verifying OTP
 type VerifyOTPRequest struct {
-	PhoneNumber string `json:"phone_number" binding:"required" validate:"required"`
-	Code        string `json:"code" binding:"required" validate:"required,len=6"`
+	PhoneNumber string `json:"phone_number" validate:"required"`
+	Code        string `json:"code" validate:"required,len=6,numeric"`
 }
 
 // OTPResponse represents the response for OTP operations
@@ -0,0 +1,9 @@
+package dto
+
+// PageInfo describes the position of a page in a cursor-paginated listing.
+// Pass NextCursor as the cursor query parameter to fetch the next page.
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
@@ -1,15 +1,49 @@
 package dto
 
-// CreateSubscriptionRequest represents the request body for creating a subscription
+import "time"
+
+// CreateSubscriptionRequest represents the request body for creating a subscription.
+// Currency defaults to INR when omitted. Amount is a pointer so that an
+// explicit 0, e.g. for a free trial, passes required.
 type CreateSubscriptionRequest struct {
-	Name   string  `json:"name" binding:"required" validate:"required,min=1,max=100"`
-	Amount float64 `json:"amount" binding:"required" validate:"required,min=0"`
+	Name     string   `json:"name" validate:"required,min=1,max=100"`
+	Amount   *float64 `json:"amount" validate:"required,min=0,money"`
+	Currency string   `json:"currency,omitempty" validate:"omitempty,currency"`
+	Category string   `json:"category,omitempty" validate:"omitempty,max=50"`
+}
+
+// ListSubscriptionsQuery represents the query parameters for listing
+// subscriptions. Sort is a field name, prefixed with "-" for descending
+// order; dates are RFC 3339.
+type ListSubscriptionsQuery struct {
+	Limit         int        `form:"limit" validate:"omitempty,min=1"`
+	Cursor        string     `form:"cursor"`
+	Sort          string     `form:"sort" validate:"omitempty,oneof=name -name amount -amount created_at -created_at"`
+	Name          string     `form:"name" validate:"omitempty,max=100"`
+	MinAmount     *float64   `form:"min_amount" validate:"omitempty,min=0"`
+	MaxAmount     *float64   `form:"max_amount" validate:"omitempty,min=0"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Category      string     `form:"category" validate:"omitempty,max=50"`
 }
 
-// UpdateSubscriptionRequest represents the request body for updating a subscription
+// UpdateSubscriptionRequest represents the request body for replacing a
+// subscription with PUT. Omitting currency keeps the current one; omitting
+// category clears it. Amount may be 0, as on create.
 type UpdateSubscriptionRequest struct {
-	Name   string  `json:"name" validate:"required,min=1,max=100"`
-	Amount float64 `json:"amount" validate:"required,min=0"`
+	Name     string   `json:"name" validate:"required,min=1,max=100"`
+	Amount   *float64 `json:"amount" validate:"required,min=0,money"`
+	Currency string   `json:"currency,omitempty" validate:"omitempty,currency"`
+	Category string   `json:"category,omitempty" validate:"omitempty,max=50"`
+}
+
+// PatchSubscriptionRequest represents the request body for a partial update
+// with PATCH. Only the fields present are changed.
+type PatchSubscriptionRequest struct {
+	Name     *string  `json:"name" validate:"omitempty,min=1,max=100"`
+	Amount   *float64 `json:"amount" validate:"omitempty,min=0,money"`
+	Currency *string  `json:"currency" validate:"omitempty,currency"`
+	Category *string  `json:"category" validate:"omitempty,max=50"`
 }
 
 // SubscriptionResponse represents the subscription data in API responses
@@ -17,13 +51,18 @@ type SubscriptionResponse struct {
 	ID        uint    `json:"id"`
 	Name      string  `json:"name"`
 	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+	Category  string  `json:"category,omitempty"`
 	UserID    uint    `json:"user_id"`
+	Version   uint    `json:"version"`
 	CreatedAt string  `json:"created_at"`
 	UpdatedAt string  `json:"updated_at"`
 }
 
-// SubscriptionsResponse represents the response for subscription list operations
+// SubscriptionsResponse represents the response for subscription list operations.
+// Total counts every subscription matching the filters, across all pages.
 type SubscriptionsResponse struct {
 	Subscriptions []*SubscriptionResponse `json:"subscriptions"`
-	Total         int                     `json:"total"`
+	Total         int64                   `json:"total"`
+	Pagination    PageInfo                `json:"pagination"`
 }
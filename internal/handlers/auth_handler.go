@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"log/slog"
+
 	"github.com/gin-gonic/gin"
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
 	"github.com/hardiksharma/clarityfin-api/internal/dto"
@@ -10,28 +12,38 @@ import (
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
 	userUseCase domain.UserUseCase
-	otpUseCase  domain.OTPUseCase
+	log         *slog.Logger
 }
 
+// errOTPCodeRequired is returned when RegisterWithOTP is called without a code
+var errOTPCodeRequired = domain.NewValidationError("validation_failed", "OTP code required",
+	domain.FieldError{Field: "otp_code", Code: "required", Message: "is required"})
+
 // NewAuthHandler creates a new instance of AuthHandler
-func NewAuthHandler(userUseCase domain.UserUseCase, otpUseCase domain.OTPUseCase) *AuthHandler {
+func NewAuthHandler(userUseCase domain.UserUseCase, log *slog.Logger) *AuthHandler {
 	return &AuthHandler{
 		userUseCase: userUseCase,
-		otpUseCase:  otpUseCase,
+		log:         log,
 	}
 }
 
-// Register handles user registration
+// Register handles user registration. If an OTP code is supplied the
+// registration is verified the same way as RegisterWithOTP.
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, err.Error())
+		respondError(c, h.log, bindError(err))
 		return
 	}
 
-	err := h.userUseCase.Register(req.PhoneNumber, req.Password)
+	if req.OTPCode != "" {
+		h.registerWithOTP(c, req)
+		return
+	}
+
+	err := h.userUseCase.Register(c.Request.Context(), req.PhoneNumber, req.Password, req.Language)
 	if err != nil {
-		response.BadRequest(c, err.Error())
+		respondError(c, h.log, err)
 		return
 	}
 
@@ -42,21 +54,23 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) RegisterWithOTP(c *gin.Context) {
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, err.Error())
+		respondError(c, h.log, bindError(err))
 		return
 	}
 
-	// Verify OTP first
-	valid, err := h.otpUseCase.VerifyOTP(req.PhoneNumber, req.OTPCode)
-	if err != nil || !valid {
-		response.BadRequest(c, "Invalid OTP")
+	if req.OTPCode == "" {
+		respondError(c, h.log, errOTPCodeRequired)
 		return
 	}
 
-	// Register user
-	err = h.userUseCase.Register(req.PhoneNumber, req.Password)
+	h.registerWithOTP(c, req)
+}
+
+// registerWithOTP verifies the OTP and creates the user atomically
+func (h *AuthHandler) registerWithOTP(c *gin.Context, req dto.RegisterRequest) {
+	err := h.userUseCase.RegisterWithOTP(c.Request.Context(), req.PhoneNumber, req.Password, req.Language, req.OTPCode)
 	if err != nil {
-		response.BadRequest(c, err.Error())
+		respondError(c, h.log, err)
 		return
 	}
 
@@ -67,13 +81,13 @@ func (h *AuthHandler) RegisterWithOTP(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, err.Error())
+		respondError(c, h.log, bindError(err))
 		return
 	}
 
-	token, err := h.userUseCase.Login(req.PhoneNumber, req.Password)
+	token, err := h.userUseCase.Login(c.Request.Context(), req.PhoneNumber, req.Password)
 	if err != nil {
-		response.Unauthorized(c, err.Error())
+		respondError(c, h.log, err)
 		return
 	}
 
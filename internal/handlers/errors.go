@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/hardiksharma/clarityfin-api/pkg/response"
+	"github.com/hardiksharma/clarityfin-api/pkg/validation"
+)
+
+// errInvalidRequestBody is returned when the request body cannot be decoded
+var errInvalidRequestBody = domain.NewValidationError("invalid_request_body", "request body is not valid JSON")
+
+// errRequestTooLarge is returned when the body exceeds the configured limit
+var errRequestTooLarge = domain.NewTooLargeError("request_too_large", "request body is too large")
+
+// errInvalidQuery is returned when a query parameter cannot be parsed
+var errInvalidQuery = domain.NewValidationError("invalid_query", "query parameters are malformed")
+
+// statusCodes maps each domain error kind to its HTTP status
+var statusCodes = map[domain.ErrorKind]int{
+	domain.KindValidation:   http.StatusBadRequest,
+	domain.KindNotFound:     http.StatusNotFound,
+	domain.KindConflict:     http.StatusConflict,
+	domain.KindUnauthorized: http.StatusUnauthorized,
+	domain.KindRateLimited:  http.StatusTooManyRequests,
+
+	domain.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	domain.KindPreconditionRequired: http.StatusPreconditionRequired,
+	domain.KindTooLarge:             http.StatusRequestEntityTooLarge,
+}
+
+// respondError writes the error envelope for err. Domain errors keep their
+// code and message; anything else is logged and reported as an opaque 500.
+func respondError(c *gin.Context, log *slog.Logger, err error) {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) && domainErr.Kind != domain.KindInternal {
+		details := make([]response.FieldError, len(domainErr.Fields))
+		for i, f := range domainErr.Fields {
+			details[i] = response.FieldError{Field: f.Field, Code: f.Code, Message: f.Message}
+		}
+		response.Error(c, statusCodes[domainErr.Kind], domainErr.Code, domainErr.Message, details...)
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		response.Error(c, http.StatusGatewayTimeout, "request_timeout", "The request took too long to complete")
+		return
+	}
+
+	log.ErrorContext(c.Request.Context(), "request failed", slog.String("error", err.Error()))
+	response.InternalServerError(c)
+}
+
+// bindError converts an error from ShouldBindJSON into a validation error
+// with one detail per invalid field
+func bindError(err error) error {
+	if fields := fieldErrors(err, ""); len(fields) > 0 {
+		return domain.NewValidationError("validation_failed", "request validation failed", fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return errInvalidRequestBody.WithFields(domain.FieldError{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: "must be a " + typeErr.Type.String(),
+		})
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errRequestTooLarge
+	}
+
+	if field, ok := unknownField(err); ok {
+		return domain.NewValidationError(errInvalidRequestBody.Code, "request body contains an unknown field", domain.FieldError{
+			Field:   field,
+			Code:    "unknown",
+			Message: "is not a known field",
+		})
+	}
+
+	return errInvalidRequestBody.Wrap(err)
+}
+
+// unknownField returns the field named by the error the JSON decoder reports
+// when unknown fields are disallowed. encoding/json has no error type for
+// this, so it matches the message text, `json: unknown field "name"`, and
+// must be revisited if the standard library changes that wording.
+func unknownField(err error) (string, bool) {
+	field, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+	return strings.Trim(field, `"`), true
+}
+
+// fieldErrors returns one detail per field that failed validation. Fields
+// of slice elements are prefixed with their index, e.g. "[2].amount".
+func fieldErrors(err error, prefix string) []domain.FieldError {
+	var sliceErrs binding.SliceValidationError
+	if errors.As(err, &sliceErrs) {
+		var fields []domain.FieldError
+		for i, elemErr := range sliceErrs {
+			if elemErr != nil {
+				fields = append(fields, fieldErrors(elemErr, fmt.Sprintf("%s[%d].", prefix, i))...)
+			}
+		}
+		return fields
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+	fields := make([]domain.FieldError, len(validationErrs))
+	for i, fe := range validationErrs {
+		fields[i] = domain.FieldError{
+			Field:   prefix + fe.Field(),
+			Code:    fe.Tag(),
+			Message: validation.Message(fe),
+		}
+	}
+	return fields
+}
+
+// bindQueryError converts an error from ShouldBindQuery like bindError, but
+// reports unparsable values as invalid_query
+func bindQueryError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return bindError(err)
+	}
+	return errInvalidQuery.Wrap(err)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+)
+
+// errPreconditionRequired is returned for a write without If-Match when
+// If-Match is required
+var errPreconditionRequired = domain.NewPreconditionRequiredError("precondition_required",
+	"If-Match header with the resource's ETag is required")
+
+// entityTag formats a resource version as a strong ETag
+func entityTag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// ifMatchVersion returns the version named by the If-Match header. It
+// returns 0, meaning any version, when the header is "*" or is absent and
+// not required. A tag this API did not issue can never match and is
+// reported as a version conflict.
+func ifMatchVersion(c *gin.Context, required bool) (uint, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	switch header {
+	case "":
+		if required {
+			return 0, errPreconditionRequired
+		}
+		return 0, nil
+	case "*":
+		return 0, nil
+	}
+
+	// Only a single strong tag is supported
+	if len(header) < 3 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, domain.ErrVersionConflict
+	}
+	version, err := strconv.ParseUint(header[1:len(header)-1], 10, 32)
+	if err != nil || version == 0 {
+		return 0, domain.ErrVersionConflict
+	}
+	return uint(version), nil
+}
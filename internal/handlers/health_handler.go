@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hardiksharma/clarityfin-api/internal/dto"
+)
+
+// HealthCheck is a named readiness check for a dependency
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthHandler serves liveness and readiness probes
+type HealthHandler struct {
+	checks       []HealthCheck
+	timeout      time.Duration
+	log          *slog.Logger
+	shuttingDown atomic.Bool
+}
+
+// NewHealthHandler creates a new instance of HealthHandler. Each readiness
+// check must complete within timeout.
+func NewHealthHandler(timeout time.Duration, log *slog.Logger, checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{
+		checks:  checks,
+		timeout: timeout,
+		log:     log,
+	}
+}
+
+// SetShuttingDown marks the service as not ready so orchestrators stop
+// routing traffic to it while in-flight requests drain
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Liveness reports that the process is up and serving requests
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.HealthResponse{Status: "ok"})
+}
+
+// Readiness runs every dependency check concurrently and reports 503 if any
+// of them fail or the service is shutting down. Probes are unauthenticated,
+// so failures are logged and the response only names the failed checks.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if h.shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, dto.HealthResponse{Status: "shutting_down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	results := make([]dto.HealthCheckResult, len(h.checks))
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check.Check(ctx)
+
+			results[i] = dto.HealthCheckResult{
+				Name:      check.Name,
+				Status:    "ok",
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				results[i].Status = "fail"
+				h.log.WarnContext(ctx, "readiness check failed",
+					slog.String("check", check.Name),
+					slog.String("error", err.Error()))
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	status, code := "ok", http.StatusOK
+	for _, result := range results {
+		if result.Status != "ok" {
+			status, code = "unavailable", http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	c.JSON(code, dto.HealthResponse{Status: status, Checks: results})
+}
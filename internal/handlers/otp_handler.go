@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"log/slog"
+
 	"github.com/gin-gonic/gin"
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
 	"github.com/hardiksharma/clarityfin-api/internal/dto"
@@ -10,12 +12,14 @@ import (
 // OTPHandler handles OTP-related HTTP requests
 type OTPHandler struct {
 	otpUseCase domain.OTPUseCase
+	log        *slog.Logger
 }
 
 // NewOTPHandler creates a new instance of OTPHandler
-func NewOTPHandler(otpUseCase domain.OTPUseCase) *OTPHandler {
+func NewOTPHandler(otpUseCase domain.OTPUseCase, log *slog.Logger) *OTPHandler {
 	return &OTPHandler{
 		otpUseCase: otpUseCase,
+		log:        log,
 	}
 }
 
@@ -23,35 +27,68 @@ func NewOTPHandler(otpUseCase domain.OTPUseCase) *OTPHandler {
 func (h *OTPHandler) SendOTP(c *gin.Context) {
 	var req dto.SendOTPRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, err.Error())
+		respondError(c, h.log, bindError(err))
 		return
 	}
 
-	err := h.otpUseCase.SendOTP(req.PhoneNumber)
+	delivery := domain.OTPDelivery{
+		Channel: domain.OTPChannelSMS,
+		Purpose: domain.OTPPurposeVerification,
+		Locale:  c.GetHeader("Accept-Language"),
+	}
+	if req.Channel != "" {
+		delivery.Channel = domain.OTPChannel(req.Channel)
+	}
+	if req.Purpose != "" {
+		delivery.Purpose = domain.OTPPurpose(req.Purpose)
+	}
+
+	err := h.otpUseCase.SendOTP(c.Request.Context(), req.PhoneNumber, delivery)
 	if err != nil {
-		response.InternalServerError(c, "Failed to send OTP")
+		respondError(c, h.log, err)
 		return
 	}
 
 	response.Success(c, nil, "OTP sent successfully")
 }
 
-// VerifyOTP handles OTP verification
-func (h *OTPHandler) VerifyOTP(c *gin.Context) {
-	var req dto.VerifyOTPRequest
+// ResendOTP handles re-delivering the current OTP, e.g. over voice when SMS
+// did not arrive
+func (h *OTPHandler) ResendOTP(c *gin.Context) {
+	var req dto.ResendOTPRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, err.Error())
+		respondError(c, h.log, bindError(err))
 		return
 	}
 
-	valid, err := h.otpUseCase.VerifyOTP(req.PhoneNumber, req.Code)
+	delivery := domain.OTPDelivery{
+		Channel: domain.OTPChannel(req.Channel),
+		Locale:  c.GetHeader("Accept-Language"),
+	}
+
+	err := h.otpUseCase.ResendOTP(c.Request.Context(), req.PhoneNumber, delivery)
 	if err != nil {
-		response.BadRequest(c, "Invalid OTP")
+		respondError(c, h.log, err)
 		return
 	}
 
-	if !valid {
-		response.BadRequest(c, "Invalid OTP")
+	response.Success(c, nil, "OTP resent successfully")
+}
+
+// VerifyOTP handles OTP verification
+func (h *OTPHandler) VerifyOTP(c *gin.Context) {
+	var req dto.VerifyOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, h.log, bindError(err))
+		return
+	}
+
+	valid, err := h.otpUseCase.VerifyOTP(c.Request.Context(), req.PhoneNumber, req.Code)
+	if err == nil && !valid {
+		err = domain.ErrInvalidOTP
+	}
+	if err != nil {
+		respondError(c, h.log, err)
 		return
 	}
 
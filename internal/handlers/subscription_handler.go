@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"path"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
 	"github.com/hardiksharma/clarityfin-api/internal/dto"
+	"github.com/hardiksharma/clarityfin-api/pkg/pagination"
 	"github.com/hardiksharma/clarityfin-api/pkg/response"
 )
 
@@ -13,53 +20,112 @@ import (
 type SubscriptionHandler struct {
 	subscriptionUseCase domain.SubscriptionUseCase
 	userService         domain.UserService
+	requireIfMatch      bool
+	log                 *slog.Logger
 }
 
-// NewSubscriptionHandler creates a new instance of SubscriptionHandler
-func NewSubscriptionHandler(subscriptionUseCase domain.SubscriptionUseCase, userService domain.UserService) *SubscriptionHandler {
+// errInvalidSubscriptionID is returned when the :id path parameter is not a
+// positive integer
+var errInvalidSubscriptionID = domain.NewValidationError("validation_failed", "invalid subscription ID",
+	domain.FieldError{Field: "id", Code: "uint", Message: "must be a positive integer"})
+
+// errInvalidCursor is returned for a cursor that is malformed or was issued
+// for a different sort order
+var errInvalidCursor = domain.NewValidationError("invalid_cursor", "invalid pagination cursor",
+	domain.FieldError{Field: "cursor", Code: "cursor", Message: "must be a next_cursor value returned for the same sort"})
+
+// errInvalidSort is returned for an unknown sort field
+var errInvalidSort = domain.NewValidationError("validation_failed", "invalid sort",
+	domain.FieldError{Field: "sort", Code: "oneof", Message: "must be one of: " + strings.Join(domain.SubscriptionSortFields, ", ")})
+
+// listRangeError reports filter ranges that cannot match anything, which
+// would otherwise return an empty page without explanation
+func listRangeError(query dto.ListSubscriptionsQuery) error {
+	var fields []domain.FieldError
+	if query.MinAmount != nil && query.MaxAmount != nil && *query.MinAmount > *query.MaxAmount {
+		fields = append(fields, domain.FieldError{Field: "max_amount", Code: "gtefield", Message: "must not be less than min_amount"})
+	}
+	if query.CreatedAfter != nil && query.CreatedBefore != nil && !query.CreatedAfter.Before(*query.CreatedBefore) {
+		fields = append(fields, domain.FieldError{Field: "created_before", Code: "gtfield", Message: "must be after created_after"})
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return domain.NewValidationError("validation_failed", "request validation failed", fields...)
+}
+
+// NewSubscriptionHandler creates a new instance of SubscriptionHandler.
+// requireIfMatch rejects updates and deletes that do not send If-Match.
+func NewSubscriptionHandler(subscriptionUseCase domain.SubscriptionUseCase, userService domain.UserService, requireIfMatch bool, log *slog.Logger) *SubscriptionHandler {
 	return &SubscriptionHandler{
 		subscriptionUseCase: subscriptionUseCase,
 		userService:         userService,
+		requireIfMatch:      requireIfMatch,
+		log:                 log,
 	}
 }
 
-// GetSubscriptions retrieves all subscriptions for the authenticated user
+// GetSubscriptions retrieves one page of the authenticated user's
+// subscriptions, filtered and sorted by the query parameters
 func (h *SubscriptionHandler) GetSubscriptions(c *gin.Context) {
-	userPhone, exists := c.Get("user_phone")
-	if !exists {
-		response.Unauthorized(c, "User not authenticated")
+	var query dto.ListSubscriptionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, h.log, bindQueryError(err))
 		return
 	}
 
-	// Get user by phone number to get user ID
-	user, err := h.userService.GetByPhoneNumber(userPhone.(string))
+	if err := listRangeError(query); err != nil {
+		respondError(c, h.log, err)
+		return
+	}
+
+	sort, err := pagination.ParseSort(query.Sort, domain.SubscriptionSortFields, domain.DefaultSubscriptionSort)
+	if err != nil {
+		respondError(c, h.log, errInvalidSort)
+		return
+	}
+	page, err := pagination.NewRequest(query.Limit, sort, query.Cursor)
 	if err != nil {
-		response.InternalServerError(c, "Failed to get user")
+		respondError(c, h.log, errInvalidCursor)
 		return
 	}
 
-	subscriptions, err := h.subscriptionUseCase.GetUserSubscriptions(user.ID)
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	filter := domain.SubscriptionFilter{
+		Name:          query.Name,
+		MinAmount:     query.MinAmount,
+		MaxAmount:     query.MaxAmount,
+		CreatedAfter:  query.CreatedAfter,
+		CreatedBefore: query.CreatedBefore,
+		Category:      query.Category,
+	}
+	result, err := h.subscriptionUseCase.ListSubscriptions(c.Request.Context(), user.ID, filter, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		err = errInvalidCursor
+	}
 	if err != nil {
-		response.InternalServerError(c, "Failed to get subscriptions")
+		respondError(c, h.log, err)
 		return
 	}
 
 	// Convert domain models to DTOs
-	subscriptionResponses := make([]*dto.SubscriptionResponse, len(subscriptions))
-	for i, sub := range subscriptions {
-		subscriptionResponses[i] = &dto.SubscriptionResponse{
-			ID:        sub.ID,
-			Name:      sub.Name,
-			Amount:    sub.Amount,
-			UserID:    sub.UserID,
-			CreatedAt: sub.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt: sub.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-		}
+	subscriptionResponses := make([]*dto.SubscriptionResponse, len(result.Items))
+	for i, sub := range result.Items {
+		subscriptionResponses[i] = toSubscriptionResponse(sub)
 	}
 
 	response.Success(c, dto.SubscriptionsResponse{
 		Subscriptions: subscriptionResponses,
-		Total:         len(subscriptionResponses),
+		Total:         result.Total,
+		Pagination: dto.PageInfo{
+			Limit:      page.Limit,
+			NextCursor: result.NextCursor,
+			HasMore:    result.HasMore(),
+		},
 	}, "Subscriptions retrieved successfully")
 }
 
@@ -67,64 +133,175 @@ func (h *SubscriptionHandler) GetSubscriptions(c *gin.Context) {
 func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 	var req dto.CreateSubscriptionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, err.Error())
+		respondError(c, h.log, bindError(err))
 		return
 	}
 
-	userPhone, exists := c.Get("user_phone")
-	if !exists {
-		response.Unauthorized(c, "User not authenticated")
+	user, ok := h.currentUser(c)
+	if !ok {
 		return
 	}
 
-	// Get user by phone number to get user ID
-	user, err := h.userService.GetByPhoneNumber(userPhone.(string))
+	subscription, err := h.subscriptionUseCase.CreateSubscription(c.Request.Context(), user.ID, req.Name, *req.Amount, req.Currency, req.Category)
 	if err != nil {
-		response.InternalServerError(c, "Failed to get user")
+		respondError(c, h.log, err)
 		return
 	}
 
-	subscription, err := h.subscriptionUseCase.CreateSubscription(user.ID, req.Name, req.Amount)
+	c.Header("ETag", entityTag(subscription.Version))
+	c.Header("Location", path.Join(c.FullPath(), strconv.FormatUint(uint64(subscription.ID), 10)))
+	response.Created(c, toSubscriptionResponse(subscription), "Subscription created successfully")
+}
+
+// GetSubscriptionByID retrieves one of the user's subscriptions
+func (h *SubscriptionHandler) GetSubscriptionByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, err.Error())
+		respondError(c, h.log, errInvalidSubscriptionID)
 		return
 	}
 
-	subscriptionResponse := &dto.SubscriptionResponse{
-		ID:        subscription.ID,
-		Name:      subscription.Name,
-		Amount:    subscription.Amount,
-		UserID:    subscription.UserID,
-		CreatedAt: subscription.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt: subscription.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.subscriptionUseCase.GetSubscriptionByID(c.Request.Context(), user.ID, uint(id))
+	if err != nil {
+		respondError(c, h.log, err)
+		return
+	}
+
+	etag := entityTag(subscription.Version)
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
 	}
 
-	response.Success(c, subscriptionResponse, "Subscription created successfully")
+	response.Success(c, toSubscriptionResponse(subscription), "Subscription retrieved successfully")
 }
 
-// GetSubscriptionByID retrieves a specific subscription
-func (h *SubscriptionHandler) GetSubscriptionByID(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+// UpdateSubscription replaces the editable fields of one of the user's
+// subscriptions. If-Match, when sent, must carry the current ETag.
+func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
+	var req dto.UpdateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, h.log, bindError(err))
+		return
+	}
+
+	h.update(c, domain.SubscriptionChanges{
+		Name:     &req.Name,
+		Amount:   req.Amount,
+		Currency: &req.Currency,
+		Category: &req.Category,
+	})
+}
+
+// PatchSubscription changes only the fields present in the request body
+func (h *SubscriptionHandler) PatchSubscription(c *gin.Context) {
+	var req dto.PatchSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, h.log, bindError(err))
+		return
+	}
+
+	h.update(c, domain.SubscriptionChanges{
+		Name:     req.Name,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+		Category: req.Category,
+	})
+}
+
+// DeleteSubscription deletes one of the user's subscriptions. If-Match, when
+// sent, must carry the current ETag.
+func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondError(c, h.log, errInvalidSubscriptionID)
+		return
+	}
+
+	version, err := ifMatchVersion(c, h.requireIfMatch)
+	if err != nil {
+		respondError(c, h.log, err)
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.subscriptionUseCase.DeleteSubscription(c.Request.Context(), user.ID, uint(id), version); err != nil {
+		respondError(c, h.log, err)
+		return
+	}
+
+	response.Success(c, nil, "Subscription deleted successfully")
+}
+
+// update applies changes to the subscription named by the :id parameter and
+// responds with the result and its new ETag
+func (h *SubscriptionHandler) update(c *gin.Context, changes domain.SubscriptionChanges) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		response.BadRequest(c, "Invalid subscription ID")
+		respondError(c, h.log, errInvalidSubscriptionID)
+		return
+	}
+
+	version, err := ifMatchVersion(c, h.requireIfMatch)
+	if err != nil {
+		respondError(c, h.log, err)
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
 		return
 	}
 
-	subscription, err := h.subscriptionUseCase.GetSubscriptionByID(uint(id))
+	subscription, err := h.subscriptionUseCase.UpdateSubscription(c.Request.Context(), user.ID, uint(id), version, changes)
 	if err != nil {
-		response.NotFound(c, "Subscription not found")
+		respondError(c, h.log, err)
 		return
 	}
 
-	subscriptionResponse := &dto.SubscriptionResponse{
+	c.Header("ETag", entityTag(subscription.Version))
+	response.Success(c, toSubscriptionResponse(subscription), "Subscription updated successfully")
+}
+
+// currentUser loads the authenticated user, writing an error response and
+// returning false if that fails
+func (h *SubscriptionHandler) currentUser(c *gin.Context) (*domain.User, bool) {
+	userPhone, exists := c.Get("user_phone")
+	if !exists {
+		response.Unauthorized(c, "unauthenticated", "User not authenticated")
+		return nil, false
+	}
+
+	user, err := h.userService.GetByPhoneNumber(c.Request.Context(), userPhone.(string))
+	if err != nil {
+		respondError(c, h.log, err)
+		return nil, false
+	}
+	return user, true
+}
+
+// toSubscriptionResponse converts a subscription to its API representation
+func toSubscriptionResponse(subscription *domain.Subscription) *dto.SubscriptionResponse {
+	return &dto.SubscriptionResponse{
 		ID:        subscription.ID,
 		Name:      subscription.Name,
 		Amount:    subscription.Amount,
+		Currency:  subscription.Currency,
+		Category:  subscription.Category,
 		UserID:    subscription.UserID,
-		CreatedAt: subscription.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt: subscription.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		Version:   subscription.Version,
+		CreatedAt: subscription.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: subscription.UpdatedAt.UTC().Format(time.RFC3339),
 	}
-
-	response.Success(c, subscriptionResponse, "Subscription retrieved successfully")
 }
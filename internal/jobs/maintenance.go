@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+)
+
+// ExpiredOTPCleanup returns a job that purges expired OTPs
+func ExpiredOTPCleanup(otpRepo domain.OTPRepository, interval time.Duration) Job {
+	return Job{
+		Name:     "expired-otp-cleanup",
+		Interval: interval,
+		Run: func(ctx context.Context) (int64, error) {
+			return otpRepo.DeleteExpired(ctx)
+		},
+	}
+}
+
+// ExpiredIdempotencyKeyCleanup returns a job that purges expired idempotency keys
+func ExpiredIdempotencyKeyCleanup(repo domain.IdempotencyRepository, interval time.Duration) Job {
+	return Job{
+		Name:     "expired-idempotency-key-cleanup",
+		Interval: interval,
+		Run: func(ctx context.Context) (int64, error) {
+			return repo.DeleteExpired(ctx)
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("github.com/hardiksharma/clarityfin-api/internal/jobs")
+
+// Job represents a periodic maintenance task
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Run performs one pass of the job and returns the number of rows affected
+	Run func(ctx context.Context) (int64, error)
+}
+
+// Scheduler runs registered jobs on their own intervals in the background
+type Scheduler struct {
+	jobs   []Job
+	jitter time.Duration
+	log    *slog.Logger
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a new Scheduler. Each job's first run is delayed by a
+// random duration up to jitter so that several instances don't fire together.
+func NewScheduler(jitter time.Duration, log *slog.Logger) *Scheduler {
+	return &Scheduler{jitter: jitter, log: log}
+}
+
+// Register adds a job to the scheduler. Jobs with a non-positive interval are
+// treated as disabled and skipped.
+func (s *Scheduler) Register(job Job) {
+	if job.Interval <= 0 {
+		s.log.Info("job disabled", slog.String("job", job.Name), slog.Duration("interval", job.Interval))
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start launches every registered job in its own goroutine
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Stop cancels all running jobs and waits for in-flight runs to finish
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// loop runs a single job until the context is cancelled
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	var delay time.Duration
+	if s.jitter > 0 {
+		delay = time.Duration(rand.Int63n(int64(s.jitter)))
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			s.run(ctx, job)
+			timer.Reset(job.Interval)
+		}
+	}
+}
+
+// run executes one pass of a job and logs its outcome
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	ctx, span := tracer.Start(ctx, "job "+job.Name)
+	defer span.End()
+
+	start := time.Now()
+	rows, err := job.Run(ctx)
+	span.SetAttributes(attribute.Int64("job.rows_affected", rows))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.log.ErrorContext(ctx, "job failed",
+			slog.String("job", job.Name),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+			slog.String("error", err.Error()))
+		return
+	}
+	s.log.InfoContext(ctx, "job finished",
+		slog.String("job", job.Name),
+		slog.Int64("rows", rows),
+		slog.Int64("duration_ms", time.Since(start).Milliseconds()))
+}
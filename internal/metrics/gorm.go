@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startTimeKey = "metrics:start_time"
+
+// gormPlugin times every GORM operation and records it with ObserveDBQuery
+type gormPlugin struct {
+	metrics *Metrics
+}
+
+// GormPlugin returns a GORM plugin that records query durations. Install it
+// with db.Use.
+func GormPlugin(m *Metrics) gorm.Plugin {
+	return &gormPlugin{metrics: m}
+}
+
+func (p *gormPlugin) Name() string {
+	return "metrics"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	// Each before hook runs ahead of every other callback of its operation
+	// and each after hook runs last, so the timing covers the whole operation
+	errs := []error{
+		cb.Create().Before("*").Register("metrics:before_create", p.start),
+		cb.Create().After("*").Register("metrics:after_create", p.observe("create")),
+		cb.Query().Before("*").Register("metrics:before_query", p.start),
+		cb.Query().After("*").Register("metrics:after_query", p.observe("query")),
+		cb.Update().Before("*").Register("metrics:before_update", p.start),
+		cb.Update().After("*").Register("metrics:after_update", p.observe("update")),
+		cb.Delete().Before("*").Register("metrics:before_delete", p.start),
+		cb.Delete().After("*").Register("metrics:after_delete", p.observe("delete")),
+		cb.Row().Before("*").Register("metrics:before_row", p.start),
+		cb.Row().After("*").Register("metrics:after_row", p.observe("row")),
+		cb.Raw().Before("*").Register("metrics:before_raw", p.start),
+		cb.Raw().After("*").Register("metrics:after_raw", p.observe("raw")),
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *gormPlugin) start(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+func (p *gormPlugin) observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		p.metrics.ObserveDBQuery(operation, table, time.Since(start))
+	}
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "clarityfin"
+
+// Outcome label values
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeInvalid = "invalid"
+)
+
+// Metrics holds the application's Prometheus collectors. Every collector is
+// registered on a private registry together with the Go runtime and process
+// collectors, and served by Handler.
+type Metrics struct {
+	registry         *prometheus.Registry
+	httpRequests     *prometheus.CounterVec
+	httpDuration     *prometheus.HistogramVec
+	dbQueryDuration  *prometheus.HistogramVec
+	otpSends         *prometheus.CounterVec
+	otpVerifications *prometheus.CounterVec
+	logins           *prometheus.CounterVec
+}
+
+// New creates and registers all collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Database query latency by operation and table.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+		otpSends: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "otp_sends_total",
+			Help:      "OTP deliveries by provider, channel and outcome.",
+		}, []string{"provider", "channel", "outcome"}),
+		otpVerifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "otp_verifications_total",
+			Help:      "OTP verifications by provider and outcome.",
+		}, []string{"provider", "outcome"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_logins_total",
+			Help:      "Login attempts by outcome.",
+		}, []string{"outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.dbQueryDuration,
+		m.otpSends,
+		m.otpVerifications,
+		m.logins,
+	)
+
+	return m
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest records a finished HTTP request. route must be the
+// route template, e.g. "/api/v1/subscriptions/:id", to keep cardinality bounded.
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveDBQuery records the duration of a database query
+func (m *Metrics) ObserveDBQuery(operation, table string, duration time.Duration) {
+	m.dbQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+}
+
+// OTPSent records an OTP delivery attempt
+func (m *Metrics) OTPSent(provider, channel, outcome string) {
+	m.otpSends.WithLabelValues(provider, channel, outcome).Inc()
+}
+
+// OTPVerified records an OTP verification attempt
+func (m *Metrics) OTPVerified(provider, outcome string) {
+	m.otpVerifications.WithLabelValues(provider, outcome).Inc()
+}
+
+// Login records a login attempt
+func (m *Metrics) Login(outcome string) {
+	m.logins.WithLabelValues(outcome).Inc()
+}
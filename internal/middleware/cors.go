@@ -0,0 +1,223 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hardiksharma/clarityfin-api/internal/config"
+	"github.com/hardiksharma/clarityfin-api/pkg/response"
+)
+
+// originPattern matches an allowed origin. A host starting with "*."
+// matches any subdomain of the rest, but not the domain itself.
+type originPattern struct {
+	scheme string
+	host   string // includes the port, if any
+	suffix string // set for wildcard patterns, e.g. ".example.com"
+}
+
+func (p originPattern) matches(scheme, host string) bool {
+	if scheme != p.scheme {
+		return false
+	}
+	if p.suffix != "" {
+		return len(host) > len(p.suffix) && strings.HasSuffix(host, p.suffix)
+	}
+	return host == p.host
+}
+
+// corsPolicy is a validated CORSConfig
+type corsPolicy struct {
+	anyOrigin        bool
+	origins          []originPattern
+	methods          []string
+	anyHeader        bool
+	headers          []string // lower case
+	allowMethods     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+// CORS applies the cross-origin policy in cfg. Requests from origins that
+// are not allowed get no CORS headers, so browsers block them; preflight
+// requests asking for a method or header outside the policy are rejected
+// with 403. An empty origin list disables cross-origin access entirely.
+func CORS(cfg config.CORSConfig) (gin.HandlerFunc, error) {
+	policy, err := newCORSPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		// Responses differ by Origin, so caches must key on it
+		c.Writer.Header().Add("Vary", "Origin")
+
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			policy.handlePreflight(c, origin)
+			return
+		}
+
+		if policy.allowOrigin(origin) {
+			policy.setOriginHeaders(c, origin)
+			if policy.exposeHeaders != "" {
+				c.Header("Access-Control-Expose-Headers", policy.exposeHeaders)
+			}
+		}
+		c.Next()
+	}, nil
+}
+
+// handlePreflight answers an OPTIONS preflight without calling later handlers
+func (p *corsPolicy) handlePreflight(c *gin.Context, origin string) {
+	if !p.allowOrigin(origin) {
+		response.Error(c, http.StatusForbidden, "cors_origin_not_allowed", "Origin "+origin+" is not allowed")
+		return
+	}
+
+	method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+	if !slices.Contains(p.methods, method) {
+		response.Error(c, http.StatusForbidden, "cors_method_not_allowed", "Method "+method+" is not allowed")
+		return
+	}
+
+	requested := requestedHeaders(c.GetHeader("Access-Control-Request-Headers"))
+	if !p.anyHeader {
+		for _, h := range requested {
+			if !slices.Contains(p.headers, h) {
+				response.Error(c, http.StatusForbidden, "cors_header_not_allowed", "Header "+h+" is not allowed")
+				return
+			}
+		}
+	}
+
+	p.setOriginHeaders(c, origin)
+	c.Header("Access-Control-Allow-Methods", p.allowMethods)
+	if len(requested) > 0 {
+		c.Header("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+	if p.maxAge != "" {
+		c.Header("Access-Control-Max-Age", p.maxAge)
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// setOriginHeaders sets the headers shared by preflight and actual responses
+func (p *corsPolicy) setOriginHeaders(c *gin.Context, origin string) {
+	if p.anyOrigin && !p.allowCredentials {
+		c.Header("Access-Control-Allow-Origin", "*")
+	} else {
+		c.Header("Access-Control-Allow-Origin", origin)
+	}
+	if p.allowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowOrigin reports whether origin matches the policy
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, pattern := range p.origins {
+		if pattern.matches(u.Scheme, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// newCORSPolicy validates cfg and precomputes the header values
+func newCORSPolicy(cfg config.CORSConfig) (*corsPolicy, error) {
+	policy := &corsPolicy{allowCredentials: cfg.AllowCredentials}
+
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			policy.anyOrigin = true
+			continue
+		}
+		pattern, err := parseOriginPattern(origin)
+		if err != nil {
+			return nil, err
+		}
+		policy.origins = append(policy.origins, pattern)
+	}
+	if policy.anyOrigin && cfg.AllowCredentials {
+		return nil, errors.New(`cors: allowed_origins "*" cannot be combined with allow_credentials`)
+	}
+
+	for _, method := range cfg.AllowedMethods {
+		policy.methods = append(policy.methods, strings.ToUpper(method))
+	}
+	policy.allowMethods = strings.Join(policy.methods, ", ")
+
+	for _, header := range cfg.AllowedHeaders {
+		if header == "*" {
+			policy.anyHeader = true
+			continue
+		}
+		policy.headers = append(policy.headers, strings.ToLower(header))
+	}
+
+	policy.exposeHeaders = strings.Join(cfg.ExposedHeaders, ", ")
+
+	if cfg.MaxAge < 0 {
+		return nil, errors.New("cors: max_age must not be negative")
+	}
+	if cfg.MaxAge > 0 {
+		policy.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	return policy, nil
+}
+
+// parseOriginPattern parses an origin such as "https://app.example.com" or
+// "https://*.example.com"
+func parseOriginPattern(origin string) (originPattern, error) {
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return originPattern{}, fmt.Errorf("cors: invalid allowed origin %q (expected scheme://host[:port])", origin)
+	}
+
+	pattern := originPattern{scheme: u.Scheme, host: u.Host}
+	if strings.HasPrefix(u.Host, "*.") {
+		pattern.suffix = u.Host[1:]
+		if strings.Contains(pattern.suffix, "*") || strings.Count(pattern.suffix, ".") < 2 {
+			return originPattern{}, fmt.Errorf("cors: wildcard origin %q must be of the form scheme://*.domain.tld", origin)
+		}
+	} else if strings.Contains(u.Host, "*") {
+		return originPattern{}, fmt.Errorf("cors: wildcard origin %q must start with \"*.\"", origin)
+	}
+	return pattern, nil
+}
+
+// requestedHeaders splits an Access-Control-Request-Headers value into lower
+// case header names
+func requestedHeaders(value string) []string {
+	var headers []string
+	for _, h := range strings.Split(value, ",") {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
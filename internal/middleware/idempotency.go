@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/hardiksharma/clarityfin-api/pkg/response"
+)
+
+const (
+	// IdempotencyKeyHeader is the header clients use to make a mutating
+	// request safe to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a stored key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests that carry an
+// Idempotency-Key header safe to retry. The first request with a key is
+// processed and its response stored for ttl; retries with the same key and
+// body get the stored response, while reuse with a different body is
+// rejected. A request that neither finishes nor fails cleanly, e.g. because
+// the process crashed, holds its key for lease at most. Keys are scoped to
+// the authenticated user, so this must run after AuthMiddleware. Requests
+// without the header are not affected.
+func Idempotency(repo domain.IdempotencyRepository, ttl, lease time.Duration, log *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		owner := c.GetString("user_phone")
+		if key == "" || owner == "" || !mutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.Error(c, http.StatusBadRequest, "invalid_idempotency_key", "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.Error(c, http.StatusRequestEntityTooLarge, "request_too_large", "request body is too large")
+			return
+		}
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "invalid_request_body", "request body could not be read")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		now := time.Now()
+		record := &domain.IdempotencyRecord{
+			Owner:       owner,
+			Key:         key,
+			Fingerprint: requestFingerprint(c.Request.Method, c.Request.URL.Path, c.GetHeader("If-Match"), body),
+			LockedUntil: now.Add(lease),
+			ExpiresAt:   now.Add(ttl),
+		}
+		existing, err := repo.Reserve(ctx, record)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to reserve idempotency key", slog.String("error", err.Error()))
+			response.InternalServerError(c)
+			return
+		}
+
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != record.Fingerprint:
+				response.Error(c, http.StatusUnprocessableEntity, "idempotency_key_reused",
+					"Idempotency-Key was already used for a different request")
+			case !existing.Completed():
+				response.Error(c, http.StatusConflict, "idempotency_key_in_use",
+					"A request with this Idempotency-Key is still being processed")
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				if existing.ETag != "" {
+					c.Header("ETag", existing.ETag)
+				}
+				if existing.Location != "" {
+					c.Header("Location", existing.Location)
+				}
+				c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		// The request context may already be cancelled or past its deadline
+		storeCtx := context.WithoutCancel(ctx)
+
+		// Server errors and panics are not final; let the client retry with
+		// the same key. Deferred so it also runs while a panic unwinds to
+		// the recovery middleware.
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := repo.Release(storeCtx, record.ID); err != nil {
+				log.ErrorContext(ctx, "failed to release idempotency key", slog.String("error", err.Error()))
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		completed = true
+
+		header := recorder.Header()
+		record.StatusCode = recorder.Status()
+		record.ContentType = header.Get("Content-Type")
+		record.ETag = header.Get("ETag")
+		record.Location = header.Get("Location")
+		record.Body = recorder.body.Bytes()
+		if err := repo.Complete(storeCtx, record); err != nil {
+			log.ErrorContext(ctx, "failed to store idempotent response", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// mutatingMethod reports whether requests with method change server state
+func mutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestFingerprint identifies a request by its method, path, precondition
+// and body
+func requestFingerprint(method, path, ifMatch string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n" + ifMatch + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body as it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
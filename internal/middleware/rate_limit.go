@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hardiksharma/clarityfin-api/internal/config"
+	"github.com/hardiksharma/clarityfin-api/pkg/ratelimit"
+	"github.com/hardiksharma/clarityfin-api/pkg/response"
+)
+
+// Values for config.RateLimitRule.Key
+const (
+	RateLimitByIP     = "ip"
+	RateLimitByUser   = "user"
+	RateLimitByAPIKey = "api_key"
+)
+
+// APIKeyHeader identifies API clients for rate limiting by API key
+const APIKeyHeader = "X-API-Key"
+
+// APIKey recognizes the X-API-Key header when its SHA-256 digest is one of
+// digests (lower-case hex) and stores the digest as "api_key" for
+// rate limiting. Unknown keys are ignored, so clients cannot mint a fresh
+// bucket per request by sending random keys.
+func APIKey(digests []string) gin.HandlerFunc {
+	known := make(map[string]bool, len(digests))
+	for _, digest := range digests {
+		known[digest] = true
+	}
+
+	return func(c *gin.Context) {
+		if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" && len(known) > 0 {
+			sum := sha256.Sum256([]byte(apiKey))
+			if digest := hex.EncodeToString(sum[:]); known[digest] {
+				c.Set("api_key", digest)
+			}
+		}
+		c.Next()
+	}
+}
+
+// RateLimit limits requests with a token bucket per client, as configured by
+// rule. name keeps the buckets of different rules apart. Clients are
+// identified by IP, by the authenticated user (which requires
+// AuthMiddleware to run first) or by a known API key (which requires
+// APIKey to run first), falling back to the IP when there is no user or
+// known key. Every response carries RateLimit-* headers; refused requests get 429
+// with Retry-After. If the store fails, requests are let through.
+func RateLimit(name string, rule config.RateLimitRule, store ratelimit.Store, log *slog.Logger) (gin.HandlerFunc, error) {
+	limit := ratelimit.Limit{Requests: rule.Requests, Period: rule.Period, Burst: rule.Burst}
+	if !limit.Enabled() {
+		return func(c *gin.Context) { c.Next() }, nil
+	}
+
+	clientKey, err := rateLimitKeyFunc(rule.Key)
+	if err != nil {
+		return nil, fmt.Errorf("rate_limit %s: %w", name, err)
+	}
+
+	policy := fmt.Sprintf("%d;w=%d", limit.Capacity(), int(math.Ceil(limit.Period.Seconds())))
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		result, err := store.Take(ctx, name+":"+clientKey(c), limit)
+		if err != nil {
+			log.ErrorContext(ctx, "rate limiter unavailable", slog.String("limit", name), slog.String("error", err.Error()))
+			c.Next()
+			return
+		}
+
+		// When several limits apply, the headers describe the tightest one
+		prev, err := strconv.Atoi(c.Writer.Header().Get("RateLimit-Remaining"))
+		if err != nil || result.Remaining <= prev {
+			c.Header("RateLimit-Policy", policy)
+			c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+			c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			c.Header("RateLimit-Reset", ceilSeconds(result.Reset))
+		}
+
+		if !result.Allowed {
+			c.Header("Retry-After", ceilSeconds(result.RetryAfter))
+			response.Error(c, http.StatusTooManyRequests, "rate_limited", "Too many requests, retry later")
+			return
+		}
+		c.Next()
+	}, nil
+}
+
+// rateLimitKeyFunc returns the function that identifies the client for key
+func rateLimitKeyFunc(key string) (func(c *gin.Context) string, error) {
+	switch key {
+	case RateLimitByIP, "":
+		return func(c *gin.Context) string {
+			return "ip:" + c.ClientIP()
+		}, nil
+
+	case RateLimitByUser:
+		return func(c *gin.Context) string {
+			if user := c.GetString("user_phone"); user != "" {
+				return "user:" + user
+			}
+			return "ip:" + c.ClientIP()
+		}, nil
+
+	case RateLimitByAPIKey:
+		return func(c *gin.Context) string {
+			if digest := c.GetString("api_key"); digest != "" {
+				return "key:" + digest
+			}
+			return "ip:" + c.ClientIP()
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown key %q (expected %q, %q or %q)", key, RateLimitByIP, RateLimitByUser, RateLimitByAPIKey)
+	}
+}
+
+// ceilSeconds formats d as whole seconds, rounding up
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// idempotencyRepository implements the IdempotencyRepository interface
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new instance of IdempotencyRepository
+func NewIdempotencyRepository(db *gorm.DB) domain.IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve inserts record, relying on the unique (owner, key) index so that
+// only one of several concurrent requests with the same key wins. An expired
+// record for the key, or a reservation whose lease has run out because its
+// request crashed or hung, is discarded first.
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	db := r.db.WithContext(ctx)
+
+	now := time.Now()
+	if err := db.Where("owner = ? AND key = ?", record.Owner, record.Key).
+		Where(r.db.Where("expires_at < ?", now).
+			Or("(status_code IS NULL OR status_code = 0) AND locked_until < ?", now)).
+		Delete(&domain.IdempotencyRecord{}).Error; err != nil {
+		return nil, err
+	}
+
+	result := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner"}, {Name: "key"}},
+		DoNothing: true,
+	}).Create(record)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var existing domain.IdempotencyRecord
+	if err := db.Where("owner = ? AND key = ?", record.Owner, record.Key).First(&existing).Error; err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// Complete stores the response of a reserved request
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return r.db.WithContext(ctx).Model(&domain.IdempotencyRecord{}).Where("id = ?", record.ID).Updates(map[string]any{
+		"status_code":  record.StatusCode,
+		"content_type": record.ContentType,
+		"etag":         record.ETag,
+		"location":     record.Location,
+		"body":         record.Body,
+	}).Error
+}
+
+// Release deletes a reservation so the key can be retried
+func (r *idempotencyRepository) Release(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.IdempotencyRecord{}, id).Error
+}
+
+// DeleteExpired deletes expired records and returns the number of rows removed
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&domain.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
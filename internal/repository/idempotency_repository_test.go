@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+)
+
+// newTestRecord returns a pending reservation for key
+func newTestRecord(owner, key string, lease time.Duration) *domain.IdempotencyRecord {
+	now := time.Now()
+	return &domain.IdempotencyRecord{
+		Owner:       owner,
+		Key:         key,
+		Fingerprint: "fp",
+		LockedUntil: now.Add(lease),
+		ExpiresAt:   now.Add(time.Hour),
+	}
+}
+
+func TestIdempotencyReserveCompleteReplay(t *testing.T) {
+	repo := NewIdempotencyRepository(newTestDB(t))
+	ctx := context.Background()
+
+	record := newTestRecord("alice", "k1", time.Minute)
+	existing, err := repo.Reserve(ctx, record)
+	if err != nil || existing != nil {
+		t.Fatalf("first Reserve = %v, %v; want nil, nil", existing, err)
+	}
+
+	existing, err = repo.Reserve(ctx, newTestRecord("alice", "k1", time.Minute))
+	if err != nil {
+		t.Fatalf("second Reserve: %v", err)
+	}
+	if existing == nil || existing.Completed() {
+		t.Fatalf("second Reserve = %+v, want the pending reservation", existing)
+	}
+
+	// Keys are scoped to their owner
+	if existing, err := repo.Reserve(ctx, newTestRecord("bob", "k1", time.Minute)); err != nil || existing != nil {
+		t.Errorf("Reserve for another owner = %v, %v; want nil, nil", existing, err)
+	}
+
+	record.StatusCode = 201
+	record.ContentType = "application/json"
+	record.ETag = `"1"`
+	record.Location = "/api/v1/subscriptions/1"
+	record.Body = []byte(`{"success":true}`)
+	if err := repo.Complete(ctx, record); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	existing, err = repo.Reserve(ctx, newTestRecord("alice", "k1", time.Minute))
+	if err != nil {
+		t.Fatalf("Reserve after Complete: %v", err)
+	}
+	if existing == nil || existing.StatusCode != 201 || existing.ETag != `"1"` ||
+		existing.Location != record.Location || string(existing.Body) != string(record.Body) {
+		t.Errorf("Reserve after Complete = %+v, want the stored response", existing)
+	}
+}
+
+func TestIdempotencyReserveTakesOverExpiredLease(t *testing.T) {
+	repo := NewIdempotencyRepository(newTestDB(t))
+	ctx := context.Background()
+
+	if _, err := repo.Reserve(ctx, newTestRecord("alice", "k1", -time.Second)); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	existing, err := repo.Reserve(ctx, newTestRecord("alice", "k1", time.Minute))
+	if err != nil || existing != nil {
+		t.Errorf("Reserve after lease ran out = %v, %v; want nil, nil", existing, err)
+	}
+}
+
+func TestIdempotencyReleaseFreesKey(t *testing.T) {
+	repo := NewIdempotencyRepository(newTestDB(t))
+	ctx := context.Background()
+
+	record := newTestRecord("alice", "k1", time.Minute)
+	if _, err := repo.Reserve(ctx, record); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if err := repo.Release(ctx, record.ID); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	existing, err := repo.Reserve(ctx, newTestRecord("alice", "k1", time.Minute))
+	if err != nil || existing != nil {
+		t.Errorf("Reserve after Release = %v, %v; want nil, nil", existing, err)
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
@@ -18,27 +19,70 @@ func NewOTPRepository(db *gorm.DB) domain.OTPRepository {
 }
 
 // Create creates a new OTP in the database
-func (r *otpRepository) Create(otp *domain.OTP) error {
-	return r.db.Create(otp).Error
+func (r *otpRepository) Create(ctx context.Context, otp *domain.OTP) error {
+	return r.db.WithContext(ctx).Create(otp).Error
 }
 
-// FindByPhoneNumberAndCode finds an OTP by phone number and code
-func (r *otpRepository) FindByPhoneNumberAndCode(phoneNumber, code string) (*domain.OTP, error) {
+// FindLatestActive finds the most recent unused, unexpired OTP for a phone number
+func (r *otpRepository) FindLatestActive(ctx context.Context, phoneNumber string) (*domain.OTP, error) {
 	var otp domain.OTP
-	err := r.db.Where("phone_number = ? AND code = ? AND is_used = ? AND expires_at > ?",
-		phoneNumber, code, false, time.Now()).First(&otp).Error
+	err := r.db.WithContext(ctx).Where("phone_number = ? AND is_used = ? AND expires_at > ?",
+		phoneNumber, false, time.Now()).Order("created_at DESC").First(&otp).Error
 	if err != nil {
 		return nil, err
 	}
 	return &otp, nil
 }
 
-// MarkAsUsed marks an OTP as used
-func (r *otpRepository) MarkAsUsed(id uint) error {
-	return r.db.Model(&domain.OTP{}).Where("id = ?", id).Update("is_used", true).Error
+// ClaimResend counts a resend and records its channel in a single
+// conditional update, so concurrent resends cannot exceed the limits
+func (r *otpRepository) ClaimResend(ctx context.Context, id uint, channel domain.OTPChannel, cooldown time.Duration, maxResends int) (bool, error) {
+	return r.claim(ctx, id, cooldown, maxResends, map[string]any{
+		"channel":      channel,
+		"resend_count": gorm.Expr("resend_count + 1"),
+		"last_sent_at": time.Now(),
+	})
 }
 
-// DeleteExpired deletes expired OTPs
-func (r *otpRepository) DeleteExpired() error {
-	return r.db.Where("expires_at < ?", time.Now()).Delete(&domain.OTP{}).Error
+// Supersede retires an OTP in favour of a new code under the same
+// conditions as ClaimResend. The conditional update lets only one of
+// several concurrent requests replace it.
+func (r *otpRepository) Supersede(ctx context.Context, id uint, cooldown time.Duration, maxResends int) (bool, error) {
+	return r.claim(ctx, id, cooldown, maxResends, map[string]any{"is_used": true})
+}
+
+// claim applies updates to an unused OTP that has deliveries left and was
+// last sent at least cooldown ago, and reports whether it did. OTPs created
+// before last_sent_at existed count from created_at.
+func (r *otpRepository) claim(ctx context.Context, id uint, cooldown time.Duration, maxResends int, updates map[string]any) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&domain.OTP{}).
+		Where("id = ? AND is_used = ? AND resend_count < ? AND COALESCE(last_sent_at, created_at) <= ?",
+			id, false, maxResends, time.Now().Add(-cooldown)).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Consume marks a valid, unexpired OTP issued for purpose as used in a single
+// conditional update and returns ErrInvalidOTP if there was none to consume
+func (r *otpRepository) Consume(ctx context.Context, phoneNumber, code string, purpose domain.OTPPurpose) error {
+	result := r.db.WithContext(ctx).Model(&domain.OTP{}).
+		Where("phone_number = ? AND code = ? AND purpose = ? AND is_used = ? AND expires_at > ?",
+			phoneNumber, code, purpose, false, time.Now()).
+		Update("is_used", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvalidOTP
+	}
+	return nil
+}
+
+// DeleteExpired deletes expired OTPs and returns the number of rows removed
+func (r *otpRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&domain.OTP{})
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hardiksharma/clarityfin-api/internal/database"
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// newTestDB returns an isolated, fully migrated in-memory database that is
+// closed when the test ends
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	store, err := database.NewInMemory()
+	if err != nil {
+		t.Fatalf("NewInMemory: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store.DB
+}
+
+// createTestUser inserts a user with a phone number derived from n
+func createTestUser(t *testing.T, db *gorm.DB, n int) *domain.User {
+	t.Helper()
+
+	user := &domain.User{PhoneNumber: fmt.Sprintf("+9198000000%02d", n), Password: "hash"}
+	if err := NewUserRepository(db).Create(context.Background(), user); err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user
+}
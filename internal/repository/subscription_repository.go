@@ -1,7 +1,15 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/hardiksharma/clarityfin-api/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -16,36 +24,157 @@ func NewSubscriptionRepository(db *gorm.DB) domain.SubscriptionRepository {
 }
 
 // Create creates a new subscription in the database
-func (r *subscriptionRepository) Create(subscription *domain.Subscription) error {
-	return r.db.Create(subscription).Error
+func (r *subscriptionRepository) Create(ctx context.Context, subscription *domain.Subscription) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
 }
 
 // FindByID finds a subscription by ID
-func (r *subscriptionRepository) FindByID(id uint) (*domain.Subscription, error) {
+func (r *subscriptionRepository) FindByID(ctx context.Context, id uint) (*domain.Subscription, error) {
 	var subscription domain.Subscription
-	err := r.db.First(&subscription, id).Error
+	err := r.db.WithContext(ctx).First(&subscription, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &subscription, nil
 }
 
-// FindByUserID finds all subscriptions for a specific user
-func (r *subscriptionRepository) FindByUserID(userID uint) ([]*domain.Subscription, error) {
+// Update saves subscription only if its version still matches the stored
+// one, then bumps the version. ErrVersionConflict is returned if another
+// write got there first.
+func (r *subscriptionRepository) Update(ctx context.Context, subscription *domain.Subscription) error {
+	result := r.db.WithContext(ctx).Model(subscription).Where("version = ?", subscription.Version).Updates(map[string]any{
+		"name":     subscription.Name,
+		"amount":   subscription.Amount,
+		"currency": subscription.Currency,
+		"category": subscription.Category,
+		"version":  gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrVersionConflict
+	}
+	subscription.Version++
+	return nil
+}
+
+// Delete deletes a subscription by ID if it is still at version.
+// ErrVersionConflict is returned if it has changed.
+func (r *subscriptionRepository) Delete(ctx context.Context, id, version uint) error {
+	result := r.db.WithContext(ctx).Where("version = ?", version).Delete(&domain.Subscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrVersionConflict
+	}
+	return nil
+}
+
+// List returns one page of a user's subscriptions using keyset pagination on
+// the sort column with the ID as a tiebreaker
+func (r *subscriptionRepository) List(ctx context.Context, userID uint, filter domain.SubscriptionFilter, page pagination.Request) (*pagination.Page[*domain.Subscription], error) {
+	query := r.db.WithContext(ctx).Model(&domain.Subscription{}).Where("user_id = ?", userID)
+	query = applySubscriptionFilter(query, filter)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// The column is interpolated into SQL, so only known fields are allowed
+	column := page.Sort.Field
+	if !slices.Contains(domain.SubscriptionSortFields, column) {
+		return nil, pagination.ErrInvalidSort
+	}
+	op, direction := ">", "ASC"
+	if page.Sort.Desc {
+		op, direction = "<", "DESC"
+	}
+
+	if page.After != nil {
+		value, err := parseSubscriptionSortValue(column, page.After.Value)
+		if err != nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		query = query.Where(fmt.Sprintf("(%[1]s %[2]s ?) OR (%[1]s = ? AND id %[2]s ?)", column, op),
+			value, value, page.After.ID)
+	}
+
+	// Fetch one extra row to learn whether another page follows
 	var subscriptions []*domain.Subscription
-	err := r.db.Where("user_id = ?", userID).Find(&subscriptions).Error
+	err := query.Order(column + " " + direction).Order("id " + direction).
+		Limit(page.Limit + 1).Find(&subscriptions).Error
 	if err != nil {
 		return nil, err
 	}
-	return subscriptions, nil
+
+	result := &pagination.Page[*domain.Subscription]{Items: subscriptions, Total: total}
+	if len(subscriptions) > page.Limit {
+		result.Items = subscriptions[:page.Limit]
+		last := result.Items[page.Limit-1]
+		result.NextCursor = pagination.Cursor{
+			Sort:  page.Sort.String(),
+			Value: subscriptionSortValue(column, last),
+			ID:    last.ID,
+		}.Encode()
+	}
+
+	return result, nil
+}
+
+// applySubscriptionFilter adds a condition for every set filter field
+func applySubscriptionFilter(query *gorm.DB, filter domain.SubscriptionFilter) *gorm.DB {
+	if filter.Name != "" {
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(filter.Name))+"%")
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", *filter.MaxAmount)
+	}
+	// Timestamps are compared in UTC, as they are stored: SQLite compares
+	// them as text, so a different offset would order them wrongly
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", filter.CreatedAfter.UTC())
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", filter.CreatedBefore.UTC())
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	return query
 }
 
-// Update updates an existing subscription
-func (r *subscriptionRepository) Update(subscription *domain.Subscription) error {
-	return r.db.Save(subscription).Error
+// subscriptionSortValue formats a subscription's sort column for a cursor
+func subscriptionSortValue(column string, subscription *domain.Subscription) string {
+	switch column {
+	case "name":
+		return subscription.Name
+	case "amount":
+		return strconv.FormatFloat(subscription.Amount, 'g', -1, 64)
+	default:
+		return subscription.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// parseSubscriptionSortValue is the inverse of subscriptionSortValue
+func parseSubscriptionSortValue(column, value string) (interface{}, error) {
+	switch column {
+	case "name":
+		return value, nil
+	case "amount":
+		return strconv.ParseFloat(value, 64)
+	default:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		return t.UTC(), err
+	}
 }
 
-// Delete deletes a subscription by ID
-func (r *subscriptionRepository) Delete(id uint) error {
-	return r.db.Delete(&domain.Subscription{}, id).Error
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
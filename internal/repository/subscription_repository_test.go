@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/hardiksharma/clarityfin-api/pkg/pagination"
+)
+
+func TestSubscriptionListPagesWithoutGapsOrDuplicates(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSubscriptionRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, db, 1)
+	other := createTestUser(t, db, 2)
+
+	// Equal amounts exercise the ID tiebreaker at page boundaries
+	for _, s := range []struct {
+		name   string
+		amount float64
+	}{
+		{"Netflix", 199}, {"Spotify", 119}, {"Prime", 119}, {"Hotstar", 299}, {"iCloud", 75},
+	} {
+		if err := repo.Create(ctx, &domain.Subscription{Name: s.name, Amount: s.amount, UserID: user.ID}); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if err := repo.Create(ctx, &domain.Subscription{Name: "Other", Amount: 1, UserID: other.ID}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	sort := pagination.Sort{Field: "amount"}
+	var names []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not terminate")
+		}
+		page, err := pagination.NewRequest(2, sort, cursor)
+		if err != nil {
+			t.Fatalf("NewRequest: %v", err)
+		}
+		result, err := repo.List(ctx, user.ID, domain.SubscriptionFilter{}, page)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if result.Total != 5 {
+			t.Errorf("Total = %d, want 5", result.Total)
+		}
+		for _, s := range result.Items {
+			names = append(names, s.Name)
+		}
+		if !result.HasMore() {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	want := []string{"iCloud", "Spotify", "Prime", "Netflix", "Hotstar"}
+	if !slices.Equal(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+}
+
+func TestSubscriptionListRejectsCursorForAnotherSort(t *testing.T) {
+	cursor := pagination.Cursor{Sort: "amount", Value: "1", ID: 1}.Encode()
+	if _, err := pagination.NewRequest(2, pagination.Sort{Field: "name"}, cursor); !errors.Is(err, pagination.ErrInvalidCursor) {
+		t.Errorf("NewRequest error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestSubscriptionListFilters(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSubscriptionRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, 1)
+
+	for _, s := range []*domain.Subscription{
+		{Name: "Netflix", Amount: 199, Category: "streaming"},
+		{Name: "Netflix Mobile", Amount: 149, Category: "streaming"},
+		{Name: "Spotify", Amount: 119, Category: "music"},
+	} {
+		s.UserID = user.ID
+		if err := repo.Create(ctx, s); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	min, max := 120.0, 199.0
+	// A non-UTC bound must select the same rows as its UTC equivalent
+	after := time.Now().Add(-time.Hour).In(time.FixedZone("IST", 5*3600+1800))
+	page, _ := pagination.NewRequest(10, pagination.Sort{Field: "name"}, "")
+
+	tests := []struct {
+		name   string
+		filter domain.SubscriptionFilter
+		want   []string
+	}{
+		{"name substring", domain.SubscriptionFilter{Name: "netFLIX"}, []string{"Netflix", "Netflix Mobile"}},
+		{"amount range", domain.SubscriptionFilter{MinAmount: &min, MaxAmount: &max}, []string{"Netflix", "Netflix Mobile"}},
+		{"category", domain.SubscriptionFilter{Category: "music"}, []string{"Spotify"}},
+		{"created after", domain.SubscriptionFilter{CreatedAfter: &after}, []string{"Netflix", "Netflix Mobile", "Spotify"}},
+		{"wildcards match literally", domain.SubscriptionFilter{Name: "%"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.List(ctx, user.ID, tt.filter, page)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			var names []string
+			for _, s := range result.Items {
+				names = append(names, s.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("names = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionUpdateAndDeleteCheckVersion(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSubscriptionRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, 1)
+
+	subscription := &domain.Subscription{Name: "Netflix", Amount: 199, UserID: user.ID}
+	if err := repo.Create(ctx, subscription); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	stale := *subscription
+	subscription.Amount = 249
+	if err := repo.Update(ctx, subscription); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if subscription.Version != 2 {
+		t.Errorf("Version = %d, want 2", subscription.Version)
+	}
+
+	stale.Amount = 99
+	if err := repo.Update(ctx, &stale); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("Update with stale version error = %v, want ErrVersionConflict", err)
+	}
+	if err := repo.Delete(ctx, subscription.ID, 1); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("Delete with stale version error = %v, want ErrVersionConflict", err)
+	}
+
+	stored, err := repo.FindByID(ctx, subscription.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.Amount != 249 {
+		t.Errorf("Amount = %v, want 249", stored.Amount)
+	}
+
+	if err := repo.Delete(ctx, subscription.ID, 2); err != nil {
+		t.Errorf("Delete with current version: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// maxTransactionRetries is how many times a transaction is retried after a
+// serialization failure or deadlock before the error is returned
+const maxTransactionRetries = 3
+
+// unitOfWork implements the UnitOfWork interface
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork creates a new instance of UnitOfWork
+func NewUnitOfWork(db *gorm.DB) domain.UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+// Do runs fn with repositories bound to one transaction at the given
+// isolation level, retrying with backoff when Postgres reports a
+// serialization failure or deadlock
+func (u *unitOfWork) Do(ctx context.Context, isolation domain.Isolation, fn func(ctx context.Context, repos domain.Repositories) error) error {
+	opts := txOptions(isolation)
+	backoff := 10 * time.Millisecond
+
+	for attempt := 0; ; attempt++ {
+		err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(ctx, domain.Repositories{
+				Users:         NewUserRepository(tx),
+				Subscriptions: NewSubscriptionRepository(tx),
+				OTPs:          NewOTPRepository(tx),
+			})
+		}, opts)
+		if err == nil || !isRetryable(err) || attempt >= maxTransactionRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// txOptions maps an isolation level to the options the transaction begins
+// with. SQLite transactions are always serializable, so its driver ignores them.
+func txOptions(isolation domain.Isolation) *sql.TxOptions {
+	if isolation == domain.IsolationSerializable {
+		return &sql.TxOptions{Isolation: sql.LevelSerializable}
+	}
+	return &sql.TxOptions{Isolation: sql.LevelDefault}
+}
+
+// isRetryable reports whether err is a Postgres serialization failure
+// (40001) or deadlock (40P01), both of which can succeed when the
+// transaction is retried
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestUnitOfWorkRollsBackOnError(t *testing.T) {
+	db := newTestDB(t)
+	uow := NewUnitOfWork(db)
+	ctx := context.Background()
+
+	errAbort := errors.New("abort")
+	calls := 0
+	err := uow.Do(ctx, domain.IsolationDefault, func(ctx context.Context, repos domain.Repositories) error {
+		calls++
+		if err := repos.Users.Create(ctx, &domain.User{PhoneNumber: "+919800000001", Password: "hash"}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Do error = %v, want %v", err, errAbort)
+	}
+	if calls != 1 {
+		t.Errorf("fn called %d times, want 1 for a non-retryable error", calls)
+	}
+
+	_, err = NewUserRepository(db).FindByPhoneNumber(ctx, "+919800000001")
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindByPhoneNumber error = %v, want ErrRecordNotFound after rollback", err)
+	}
+}
+
+func TestUnitOfWorkCommits(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	err := NewUnitOfWork(db).Do(ctx, domain.IsolationSerializable, func(ctx context.Context, repos domain.Repositories) error {
+		return repos.Users.Create(ctx, &domain.User{PhoneNumber: "+919800000001", Password: "hash"})
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if _, err := NewUserRepository(db).FindByPhoneNumber(ctx, "+919800000001"); err != nil {
+		t.Errorf("FindByPhoneNumber after commit: %v", err)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pgconn.PgError{Code: "40001"}, true},
+		{&pgconn.PgError{Code: "40P01"}, true},
+		{fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "40001"}), true},
+		{&pgconn.PgError{Code: "23505"}, false},
+		{errors.New("other"), false},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
 	"gorm.io/gorm"
 )
@@ -16,14 +18,14 @@ func NewUserRepository(db *gorm.DB) domain.UserRepository {
 }
 
 // Create creates a new user in the database
-func (r *userRepository) Create(user *domain.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
 }
 
 // FindByPhoneNumber finds a user by phone number
-func (r *userRepository) FindByPhoneNumber(phoneNumber string) (*domain.User, error) {
+func (r *userRepository) FindByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.Where("phone_number = ?", phoneNumber).First(&user).Error
+	err := r.db.WithContext(ctx).Where("phone_number = ?", phoneNumber).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -31,9 +33,9 @@ func (r *userRepository) FindByPhoneNumber(phoneNumber string) (*domain.User, er
 }
 
 // FindByID finds a user by ID
-func (r *userRepository) FindByID(id uint) (*domain.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
 	var user domain.User
-	err := r.db.First(&user, id).Error
+	err := r.db.WithContext(ctx).First(&user, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -41,11 +43,11 @@ func (r *userRepository) FindByID(id uint) (*domain.User, error) {
 }
 
 // Update updates an existing user
-func (r *userRepository) Update(user *domain.User) error {
-	return r.db.Save(user).Error
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
 }
 
 // Delete deletes a user by ID
-func (r *userRepository) Delete(id uint) error {
-	return r.db.Delete(&domain.User{}, id).Error
+func (r *userRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.User{}, id).Error
 }
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/hardiksharma/clarityfin-api/pkg/phone"
+	"gorm.io/gorm"
+)
+
+// normalizePhone normalizes a phone number to E.164, reporting unparseable
+// numbers as a validation error on the phone_number field
+func normalizePhone(raw string) (string, error) {
+	normalized, err := phone.Normalize(raw)
+	if errors.Is(err, phone.ErrInvalidNumber) {
+		return "", domain.ErrInvalidPhoneNumber
+	}
+	return normalized, err
+}
+
+// notFound translates GORM's record-not-found error into notFoundErr and
+// passes every other error through unchanged
+func notFound(err error, notFoundErr *domain.Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFoundErr
+	}
+	return err
+}
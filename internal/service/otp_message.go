@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hardiksharma/clarityfin-api/internal/config"
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"golang.org/x/text/language"
+)
+
+// otpValidity is how long a generated OTP can be used
+const otpValidity = 5 * time.Minute
+
+// defaultOTPTemplate is used when no template is configured for a purpose
+const defaultOTPTemplate = "Your ClarityFin verification code is: {{.Code}}. Valid for {{.ValidMinutes}} minutes."
+
+// otpTemplateData is the data passed to OTP message templates
+type otpTemplateData struct {
+	Code         string
+	ValidMinutes int
+}
+
+// localizedTemplates holds one purpose's templates and a matcher over their locales
+type localizedTemplates struct {
+	matcher   language.Matcher
+	templates []*template.Template // same order as the matcher's tags
+}
+
+// otpMessages renders OTP message bodies per purpose and locale
+type otpMessages struct {
+	defaultLocale language.Tag
+	appHash       string
+	purposes      map[domain.OTPPurpose]*localizedTemplates
+}
+
+// newOTPMessages parses the configured templates. Every purpose always has a
+// template for the default locale, falling back to the built-in English text.
+func newOTPMessages(cfg config.OTPConfig) (*otpMessages, error) {
+	defaultLocale, err := language.Parse(cfg.DefaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTP default locale %q: %w", cfg.DefaultLocale, err)
+	}
+
+	m := &otpMessages{
+		defaultLocale: defaultLocale,
+		appHash:       cfg.AndroidAppHash,
+		purposes:      make(map[domain.OTPPurpose]*localizedTemplates),
+	}
+
+	purposes := []domain.OTPPurpose{domain.OTPPurposeVerification, domain.OTPPurposeRegistration}
+	for purpose := range cfg.Templates {
+		if p := domain.OTPPurpose(purpose); p != domain.OTPPurposeVerification && p != domain.OTPPurposeRegistration {
+			purposes = append(purposes, p)
+		}
+	}
+
+	for _, purpose := range purposes {
+		lt, err := newLocalizedTemplates(purpose, defaultLocale, cfg.Templates[string(purpose)])
+		if err != nil {
+			return nil, err
+		}
+		m.purposes[purpose] = lt
+	}
+
+	return m, nil
+}
+
+// newLocalizedTemplates parses one purpose's templates with the default locale first
+func newLocalizedTemplates(purpose domain.OTPPurpose, defaultLocale language.Tag, byLocale map[string]string) (*localizedTemplates, error) {
+	defaultBody := defaultOTPTemplate
+	tags := []language.Tag{defaultLocale}
+	bodies := []string{""}
+
+	for locale, body := range byLocale {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid locale %q in OTP templates for %s: %w", locale, purpose, err)
+		}
+		if tag == defaultLocale {
+			defaultBody = body
+			continue
+		}
+		tags = append(tags, tag)
+		bodies = append(bodies, body)
+	}
+	bodies[0] = defaultBody
+
+	lt := &localizedTemplates{matcher: language.NewMatcher(tags)}
+	for i, body := range bodies {
+		tmpl, err := template.New(fmt.Sprintf("%s/%s", purpose, tags[i])).Parse(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTP template for %s/%s: %w", purpose, tags[i], err)
+		}
+		lt.templates = append(lt.templates, tmpl)
+	}
+
+	return lt, nil
+}
+
+// render builds the message body for a code. locales are tried in order and
+// may be plain language tags or Accept-Language header values.
+func (m *otpMessages) render(purpose domain.OTPPurpose, channel domain.OTPChannel, code string, locales ...string) (string, error) {
+	lt, ok := m.purposes[purpose]
+	if !ok {
+		lt = m.purposes[domain.OTPPurposeVerification]
+	}
+
+	_, index := language.MatchStrings(lt.matcher, locales...)
+
+	data := otpTemplateData{
+		Code:         code,
+		ValidMinutes: int(otpValidity / time.Minute),
+	}
+	if channel == domain.OTPChannelVoice {
+		// Separate the digits so text-to-speech reads them one at a time
+		data.Code = strings.Join(strings.Split(code, ""), ", ")
+	}
+
+	var body strings.Builder
+	if err := lt.templates[index].Execute(&body, data); err != nil {
+		return "", err
+	}
+
+	if channel == domain.OTPChannelSMS && m.appHash != "" {
+		// The SMS Retriever API matches on the app hash at the end of the message
+		body.WriteString("\n\n")
+		body.WriteString(m.appHash)
+	}
+
+	return body.String(), nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hardiksharma/clarityfin-api/internal/config"
+	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/twilio/twilio-go"
+	twilioClient "github.com/twilio/twilio-go/client"
+	twilioApi "github.com/twilio/twilio-go/rest/api/v2010"
+)
+
+// newOTPSenders builds the senders available for the configured provider,
+// keyed by delivery channel. Provider calls give up after timeout.
+func newOTPSenders(smsConfig config.SMSConfig, timeout time.Duration, log *slog.Logger) map[domain.OTPChannel]domain.OTPSender {
+	switch smsConfig.Provider {
+	case "twilio":
+		// twilio-go takes no context, so the HTTP client's timeout bounds
+		// each call instead. A slow call is aborted with its request rather
+		// than left running in the background.
+		httpClient := &twilioClient.Client{
+			Credentials: twilioClient.NewCredentials(smsConfig.Twilio.AccountSID, smsConfig.Twilio.AuthToken),
+		}
+		httpClient.SetAccountSid(smsConfig.Twilio.AccountSID)
+		httpClient.SetTimeout(timeout)
+		client := twilio.NewRestClientWithParams(twilio.ClientParams{Client: httpClient})
+		return map[domain.OTPChannel]domain.OTPSender{
+			domain.OTPChannelSMS:      &twilioSMSSender{client: client, from: smsConfig.Twilio.FromNumber},
+			domain.OTPChannelVoice:    &twilioVoiceSender{client: client, from: smsConfig.Twilio.FromNumber},
+			domain.OTPChannelWhatsApp: &twilioWhatsAppSender{client: client, from: smsConfig.Twilio.WhatsAppFrom},
+		}
+	case "msg91":
+		return map[domain.OTPChannel]domain.OTPSender{
+			domain.OTPChannelSMS: &msg91Sender{log: log},
+		}
+	default:
+		// For development/testing, log the delivery instead of sending it
+		return map[domain.OTPChannel]domain.OTPSender{
+			domain.OTPChannelSMS:      &consoleSender{channel: domain.OTPChannelSMS, log: log},
+			domain.OTPChannelVoice:    &consoleSender{channel: domain.OTPChannelVoice, log: log},
+			domain.OTPChannelWhatsApp: &consoleSender{channel: domain.OTPChannelWhatsApp, log: log},
+		}
+	}
+}
+
+// providerName returns the provider that newOTPSenders builds senders for
+func providerName(smsConfig config.SMSConfig) string {
+	switch smsConfig.Provider {
+	case "twilio", "msg91":
+		return smsConfig.Provider
+	default:
+		return "console"
+	}
+}
+
+// providerError is a delivery failure reported by the provider. It keeps only
+// the provider's error code and HTTP status: provider messages can include
+// the destination number, which must not reach logs or traces.
+type providerError struct {
+	provider string
+	code     int
+	status   int
+}
+
+func (e *providerError) Error() string {
+	return fmt.Sprintf("%s: error %d (HTTP %d)", e.provider, e.code, e.status)
+}
+
+// twilioError replaces a Twilio API error with a providerError
+func twilioError(err error) error {
+	var restErr *twilioClient.TwilioRestError
+	if errors.As(err, &restErr) {
+		return &providerError{provider: "twilio", code: restErr.Code, status: restErr.Status}
+	}
+	return err
+}
+
+// sendErrorClass names the kind of a delivery failure without its message,
+// e.g. "twilio_21211" or "timeout"
+func sendErrorClass(err error) string {
+	var provErr *providerError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &provErr):
+		return fmt.Sprintf("%s_%d", provErr.provider, provErr.code)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "send_failed"
+	}
+}
+
+// twilioSMSSender sends OTPs as Twilio SMS messages
+type twilioSMSSender struct {
+	client *twilio.RestClient
+	from   string
+}
+
+// Send sends the OTP via Twilio SMS
+func (s *twilioSMSSender) Send(ctx context.Context, phoneNumber, body string) error {
+	params := &twilioApi.CreateMessageParams{}
+	params.SetTo(phoneNumber)
+	params.SetFrom(s.from)
+	params.SetBody(body)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := s.client.Api.CreateMessage(params)
+	return twilioError(err)
+}
+
+// twilioVoiceSender reads OTPs out over a Twilio voice call
+type twilioVoiceSender struct {
+	client *twilio.RestClient
+	from   string
+}
+
+// Send places a call that reads the message out twice
+func (s *twilioVoiceSender) Send(ctx context.Context, phoneNumber, body string) error {
+	var escaped strings.Builder
+	if err := xml.EscapeText(&escaped, []byte(body)); err != nil {
+		return err
+	}
+	say := escaped.String()
+
+	params := &twilioApi.CreateCallParams{}
+	params.SetTo(phoneNumber)
+	params.SetFrom(s.from)
+	params.SetTwiml(fmt.Sprintf("<Response><Say>%s</Say><Pause length=\"1\"/><Say>%s</Say></Response>", say, say))
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := s.client.Api.CreateCall(params)
+	return twilioError(err)
+}
+
+// twilioWhatsAppSender sends OTPs as WhatsApp messages through Twilio
+type twilioWhatsAppSender struct {
+	client *twilio.RestClient
+	from   string
+}
+
+// Send sends the OTP via WhatsApp
+func (s *twilioWhatsAppSender) Send(ctx context.Context, phoneNumber, body string) error {
+	if s.from == "" {
+		return fmt.Errorf("Twilio WhatsApp sender not configured")
+	}
+
+	params := &twilioApi.CreateMessageParams{}
+	params.SetTo("whatsapp:" + phoneNumber)
+	params.SetFrom("whatsapp:" + s.from)
+	params.SetBody(body)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := s.client.Api.CreateMessage(params)
+	return twilioError(err)
+}
+
+// msg91Sender sends OTPs via MSG91 SMS
+type msg91Sender struct {
+	log *slog.Logger
+}
+
+// Send sends the OTP via MSG91
+func (s *msg91Sender) Send(ctx context.Context, phoneNumber, body string) error {
+	// Implementation for MSG91 would go here
+	// For now, just log the delivery
+	s.log.WarnContext(ctx, "MSG91 delivery not implemented, OTP not sent",
+		slog.String("phone_number", phoneNumber))
+	return nil
+}
+
+// consoleSender logs OTP deliveries for local development. The code itself
+// is never logged; read it from the otps table.
+type consoleSender struct {
+	channel domain.OTPChannel
+	log     *slog.Logger
+}
+
+// Send logs the delivery instead of sending the message
+func (s *consoleSender) Send(ctx context.Context, phoneNumber, body string) error {
+	s.log.InfoContext(ctx, "OTP delivery skipped by console provider",
+		slog.String("phone_number", phoneNumber),
+		slog.String("channel", string(s.channel)))
+	return nil
+}
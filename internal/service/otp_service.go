@@ -1,114 +1,237 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/rand"
 	"time"
 
 	"github.com/hardiksharma/clarityfin-api/internal/config"
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
-	"github.com/twilio/twilio-go"
-	twilioApi "github.com/twilio/twilio-go/rest/api/v2010"
+	"github.com/hardiksharma/clarityfin-api/internal/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 )
 
 // otpService implements the OTPService interface
 type otpService struct {
-	otpRepo      domain.OTPRepository
-	smsConfig    config.SMSConfig
-	twilioClient *twilio.RestClient
+	otpRepo  domain.OTPRepository
+	userRepo domain.UserRepository
+	senders  map[domain.OTPChannel]domain.OTPSender
+	messages *otpMessages
+	provider string
+	log      *slog.Logger
+	metrics  *metrics.Metrics
+
+	resendCooldown time.Duration
+	maxResends     int
 }
 
-// NewOTPService creates a new instance of OTPService
-func NewOTPService(otpRepo domain.OTPRepository, smsConfig config.SMSConfig) domain.OTPService {
-	var twilioClient *twilio.RestClient
-	if smsConfig.Provider == "twilio" {
-		twilioClient = twilio.NewRestClientWithParams(twilio.ClientParams{
-			Username: smsConfig.Twilio.AccountSID,
-			Password: smsConfig.Twilio.AuthToken,
-		})
+// NewOTPService creates a new instance of OTPService. sendTimeout bounds each
+// provider call and should not exceed the request timeout.
+func NewOTPService(otpRepo domain.OTPRepository, userRepo domain.UserRepository, smsConfig config.SMSConfig, otpConfig config.OTPConfig, sendTimeout time.Duration, log *slog.Logger, m *metrics.Metrics) (domain.OTPService, error) {
+	messages, err := newOTPMessages(otpConfig)
+	if err != nil {
+		return nil, err
 	}
 
 	return &otpService{
-		otpRepo:      otpRepo,
-		smsConfig:    smsConfig,
-		twilioClient: twilioClient,
-	}
+		otpRepo:  otpRepo,
+		userRepo: userRepo,
+		senders:  newOTPSenders(smsConfig, sendTimeout, log),
+		messages: messages,
+		provider: providerName(smsConfig),
+		log:      log,
+		metrics:  m,
+
+		resendCooldown: otpConfig.ResendCooldown,
+		maxResends:     otpConfig.MaxResends,
+	}, nil
 }
 
-// GenerateOTP generates a new OTP for the given phone number
-func (s *otpService) GenerateOTP(phoneNumber string) error {
+// GenerateOTP generates a new OTP for the given phone number and sends it
+// over the requested channel. A new code replaces the active one and counts
+// as one of its deliveries, so otp.resend_cooldown and otp.max_resends bound
+// /otp/send as well as /otp/resend.
+func (s *otpService) GenerateOTP(ctx context.Context, phoneNumber string, delivery domain.OTPDelivery) (err error) {
+	ctx, span := startSpan(ctx, "OTPService.GenerateOTP")
+	defer func() { endSpan(span, err) }()
+
+	phoneNumber, err = normalizePhone(phoneNumber)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.senders[delivery.Channel]; !ok {
+		return domain.ErrOTPChannelUnsupported
+	}
+
+	resendCount, err := s.supersedeActive(ctx, phoneNumber)
+	if err != nil {
+		return err
+	}
+
 	// Generate a 6-digit OTP
 	code := fmt.Sprintf("%06d", rand.Intn(1000000))
 
-	// Set expiration time (5 minutes from now)
-	expiresAt := time.Now().Add(5 * time.Minute)
-
 	// Create OTP record
+	now := time.Now()
 	otp := &domain.OTP{
 		PhoneNumber: phoneNumber,
 		Code:        code,
-		ExpiresAt:   expiresAt,
+		Channel:     delivery.Channel,
+		Purpose:     delivery.Purpose,
+		ExpiresAt:   now.Add(otpValidity),
 		IsUsed:      false,
+		ResendCount: resendCount,
+		LastSentAt:  &now,
 	}
 
 	// Save to database
-	if err := s.otpRepo.Create(otp); err != nil {
+	if err := s.otpRepo.Create(ctx, otp); err != nil {
 		return err
 	}
 
-	// Send OTP via SMS
-	return s.SendOTP(phoneNumber, code)
+	return s.SendOTP(ctx, phoneNumber, code, delivery)
 }
 
-// VerifyOTP verifies the OTP for the given phone number
-func (s *otpService) VerifyOTP(phoneNumber, code string) (bool, error) {
-	// Find OTP in database
-	otp, err := s.otpRepo.FindByPhoneNumberAndCode(phoneNumber, code)
+// supersedeActive retires the phone number's active OTP, if any, and
+// returns the delivery count the new code inherits from it
+func (s *otpService) supersedeActive(ctx context.Context, phoneNumber string) (int, error) {
+	active, err := s.otpRepo.FindLatestActive(ctx, phoneNumber)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if active.ResendCount >= s.maxResends {
+		return 0, domain.ErrOTPSendLimitReached
+	}
+	superseded, err := s.otpRepo.Supersede(ctx, active.ID, s.resendCooldown, s.maxResends)
+	if err != nil {
+		return 0, err
+	}
+	if !superseded {
+		return 0, domain.ErrOTPSendTooSoon
+	}
+	return active.ResendCount + 1, nil
+}
+
+// ResendOTP re-delivers the latest active OTP over a possibly different
+// channel without generating a new code. The original purpose is kept.
+// Resends of one OTP are spaced by otp.resend_cooldown and capped at
+// otp.max_resends, however many IPs they come from, since voice and
+// WhatsApp deliveries cost money.
+func (s *otpService) ResendOTP(ctx context.Context, phoneNumber string, delivery domain.OTPDelivery) (err error) {
+	ctx, span := startSpan(ctx, "OTPService.ResendOTP")
+	defer func() { endSpan(span, err) }()
+
+	phoneNumber, err = normalizePhone(phoneNumber)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.senders[delivery.Channel]; !ok {
+		return domain.ErrOTPChannelUnsupported
+	}
+
+	otp, err := s.otpRepo.FindLatestActive(ctx, phoneNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrNoActiveOTP
+		}
+		return err
+	}
+
+	if otp.ResendCount >= s.maxResends {
+		return domain.ErrOTPResendLimitReached
+	}
+
+	// The resend is claimed before sending, so a failed delivery still counts
+	claimed, err := s.otpRepo.ClaimResend(ctx, otp.ID, delivery.Channel, s.resendCooldown, s.maxResends)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return domain.ErrOTPResendTooSoon
+	}
+
+	delivery.Purpose = otp.Purpose
+	return s.SendOTP(ctx, phoneNumber, otp.Code, delivery)
+}
+
+// VerifyOTP verifies and consumes a verification OTP for the given phone
+// number. The check and the update are one conditional statement, so a code
+// is accepted at most once; codes sent for registration are not accepted.
+func (s *otpService) VerifyOTP(ctx context.Context, phoneNumber, code string) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "OTPService.VerifyOTP")
+	defer func() { endSpan(span, err) }()
+
+	phoneNumber, err = normalizePhone(phoneNumber)
 	if err != nil {
 		return false, err
 	}
 
-	// Mark OTP as used
-	if err := s.otpRepo.MarkAsUsed(otp.ID); err != nil {
+	if err := s.otpRepo.Consume(ctx, phoneNumber, code, domain.OTPPurposeVerification); err != nil {
+		outcome := metrics.OutcomeFailure
+		if errors.Is(err, domain.ErrInvalidOTP) {
+			outcome = metrics.OutcomeInvalid
+		}
+		s.metrics.OTPVerified(s.provider, outcome)
 		return false, err
 	}
 
+	s.metrics.OTPVerified(s.provider, metrics.OutcomeSuccess)
 	return true, nil
 }
 
-// SendOTP sends OTP via SMS using Twilio or MSG91
-func (s *otpService) SendOTP(phoneNumber, code string) error {
-	switch s.smsConfig.Provider {
-	case "twilio":
-		return s.sendViaTwilio(phoneNumber, code)
-	case "msg91":
-		return s.sendViaMSG91(phoneNumber, code)
-	default:
-		// For development/testing, just log the OTP
-		fmt.Printf("OTP for %s: %s\n", phoneNumber, code)
-		return nil
+// SendOTP renders the OTP message in the recipient's language and delivers it
+// over the requested channel using the configured provider
+func (s *otpService) SendOTP(ctx context.Context, phoneNumber, code string, delivery domain.OTPDelivery) (err error) {
+	ctx, span := startSpan(ctx, "OTPService.SendOTP")
+	defer func() { endSpan(span, err) }()
+
+	sender, ok := s.senders[delivery.Channel]
+	if !ok {
+		return domain.ErrOTPChannelUnsupported
 	}
-}
 
-// sendViaTwilio sends OTP via Twilio SMS
-func (s *otpService) sendViaTwilio(phoneNumber, code string) error {
-	if s.twilioClient == nil {
-		return fmt.Errorf("Twilio client not initialized")
+	// A stored user preference wins over the request's Accept-Language
+	var locales []string
+	if user, err := s.userRepo.FindByPhoneNumber(ctx, phoneNumber); err == nil && user.Language != "" {
+		locales = append(locales, user.Language)
+	}
+	if delivery.Locale != "" {
+		locales = append(locales, delivery.Locale)
 	}
 
-	params := &twilioApi.CreateMessageParams{}
-	params.SetTo(phoneNumber)
-	params.SetFrom(s.smsConfig.Twilio.FromNumber)
-	params.SetBody(fmt.Sprintf("Your ClarityFin verification code is: %s. Valid for 5 minutes.", code))
+	body, err := s.messages.render(delivery.Purpose, delivery.Channel, code, locales...)
+	if err != nil {
+		return err
+	}
 
-	_, err := s.twilioClient.Api.CreateMessage(params)
-	return err
-}
+	sendCtx, sendSpan := tracer.Start(ctx, "otp."+string(delivery.Channel)+".send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("otp.provider", s.provider),
+			attribute.String("otp.channel", string(delivery.Channel)),
+		))
+	err = sender.Send(sendCtx, phoneNumber, body)
+	endSendSpan(sendSpan, err)
+	if err != nil {
+		s.metrics.OTPSent(s.provider, string(delivery.Channel), metrics.OutcomeFailure)
+		s.log.ErrorContext(ctx, "OTP delivery failed",
+			slog.String("phone_number", phoneNumber),
+			slog.String("channel", string(delivery.Channel)),
+			slog.String("error", err.Error()))
+		return err
+	}
 
-// sendViaMSG91 sends OTP via MSG91 SMS
-func (s *otpService) sendViaMSG91(phoneNumber, code string) error {
-	// Implementation for MSG91 would go here
-	// For now, just log the OTP
-	fmt.Printf("MSG91 OTP for %s: %s\n", phoneNumber, code)
+	s.metrics.OTPSent(s.provider, string(delivery.Channel), metrics.OutcomeSuccess)
 	return nil
 }
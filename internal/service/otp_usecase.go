@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
 )
 
@@ -17,11 +18,25 @@ func NewOTPUseCase(otpService domain.OTPService) domain.OTPUseCase {
 }
 
 // SendOTP handles OTP generation and sending
-func (uc *otpUseCase) SendOTP(phoneNumber string) error {
-	return uc.otpService.GenerateOTP(phoneNumber)
+func (uc *otpUseCase) SendOTP(ctx context.Context, phoneNumber string, delivery domain.OTPDelivery) (err error) {
+	ctx, span := startSpan(ctx, "OTPUseCase.SendOTP")
+	defer func() { endSpan(span, err) }()
+
+	return uc.otpService.GenerateOTP(ctx, phoneNumber, delivery)
+}
+
+// ResendOTP handles re-delivering an existing OTP over another channel
+func (uc *otpUseCase) ResendOTP(ctx context.Context, phoneNumber string, delivery domain.OTPDelivery) (err error) {
+	ctx, span := startSpan(ctx, "OTPUseCase.ResendOTP")
+	defer func() { endSpan(span, err) }()
+
+	return uc.otpService.ResendOTP(ctx, phoneNumber, delivery)
 }
 
 // VerifyOTP handles OTP verification
-func (uc *otpUseCase) VerifyOTP(phoneNumber, code string) (bool, error) {
-	return uc.otpService.VerifyOTP(phoneNumber, code)
+func (uc *otpUseCase) VerifyOTP(ctx context.Context, phoneNumber, code string) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "OTPUseCase.VerifyOTP")
+	defer func() { endSpan(span, err) }()
+
+	return uc.otpService.VerifyOTP(ctx, phoneNumber, code)
 }
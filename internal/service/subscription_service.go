@@ -1,9 +1,10 @@
 package service
 
 import (
-	"errors"
+	"context"
 
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/hardiksharma/clarityfin-api/pkg/pagination"
 )
 
 // subscriptionService implements the SubscriptionService interface
@@ -21,20 +22,29 @@ func NewSubscriptionService(subscriptionRepo domain.SubscriptionRepository, user
 }
 
 // CreateSubscription creates a new subscription for a user
-func (s *subscriptionService) CreateSubscription(userID uint, name string, amount float64) (*domain.Subscription, error) {
+func (s *subscriptionService) CreateSubscription(ctx context.Context, userID uint, name string, amount float64, currency, category string) (_ *domain.Subscription, err error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.CreateSubscription")
+	defer func() { endSpan(span, err) }()
+
 	// Verify user exists
-	_, err := s.userRepo.FindByID(userID)
+	_, err = s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, notFound(err, domain.ErrUserNotFound)
+	}
+
+	if currency == "" {
+		currency = domain.DefaultCurrency
 	}
 
 	subscription := &domain.Subscription{
-		Name:   name,
-		Amount: amount,
-		UserID: userID,
+		Name:     name,
+		Amount:   amount,
+		Currency: currency,
+		Category: category,
+		UserID:   userID,
 	}
 
-	err = s.subscriptionRepo.Create(subscription)
+	err = s.subscriptionRepo.Create(ctx, subscription)
 	if err != nil {
 		return nil, err
 	}
@@ -42,33 +52,57 @@ func (s *subscriptionService) CreateSubscription(userID uint, name string, amoun
 	return subscription, nil
 }
 
-// GetUserSubscriptions retrieves all subscriptions for a user
-func (s *subscriptionService) GetUserSubscriptions(userID uint) ([]*domain.Subscription, error) {
+// ListSubscriptions returns one page of a user's subscriptions matching filter
+func (s *subscriptionService) ListSubscriptions(ctx context.Context, userID uint, filter domain.SubscriptionFilter, page pagination.Request) (_ *pagination.Page[*domain.Subscription], err error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.ListSubscriptions")
+	defer func() { endSpan(span, err) }()
+
 	// Verify user exists
-	_, err := s.userRepo.FindByID(userID)
+	_, err = s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, notFound(err, domain.ErrUserNotFound)
 	}
 
-	return s.subscriptionRepo.FindByUserID(userID)
+	return s.subscriptionRepo.List(ctx, userID, filter, page)
 }
 
-// GetSubscriptionByID retrieves a subscription by ID
-func (s *subscriptionService) GetSubscriptionByID(id uint) (*domain.Subscription, error) {
-	return s.subscriptionRepo.FindByID(id)
+// GetSubscriptionByID retrieves one of the user's subscriptions by ID
+func (s *subscriptionService) GetSubscriptionByID(ctx context.Context, userID, id uint) (_ *domain.Subscription, err error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.GetSubscriptionByID")
+	defer func() { endSpan(span, err) }()
+
+	return s.findOwned(ctx, userID, id)
 }
 
-// UpdateSubscription updates an existing subscription
-func (s *subscriptionService) UpdateSubscription(id uint, name string, amount float64) (*domain.Subscription, error) {
-	subscription, err := s.subscriptionRepo.FindByID(id)
+// UpdateSubscription applies changes to one of the user's subscriptions. A
+// non-zero version must match the current one; zero skips that check, but a
+// concurrent write is still detected.
+func (s *subscriptionService) UpdateSubscription(ctx context.Context, userID, id, version uint, changes domain.SubscriptionChanges) (_ *domain.Subscription, err error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.UpdateSubscription")
+	defer func() { endSpan(span, err) }()
+
+	subscription, err := s.findOwned(ctx, userID, id)
 	if err != nil {
-		return nil, errors.New("subscription not found")
+		return nil, err
+	}
+	if version != 0 && subscription.Version != version {
+		return nil, domain.ErrVersionConflict
 	}
 
-	subscription.Name = name
-	subscription.Amount = amount
+	if changes.Name != nil {
+		subscription.Name = *changes.Name
+	}
+	if changes.Amount != nil {
+		subscription.Amount = *changes.Amount
+	}
+	if changes.Currency != nil && *changes.Currency != "" {
+		subscription.Currency = *changes.Currency
+	}
+	if changes.Category != nil {
+		subscription.Category = *changes.Category
+	}
 
-	err = s.subscriptionRepo.Update(subscription)
+	err = s.subscriptionRepo.Update(ctx, subscription)
 	if err != nil {
 		return nil, err
 	}
@@ -76,13 +110,32 @@ func (s *subscriptionService) UpdateSubscription(id uint, name string, amount fl
 	return subscription, nil
 }
 
-// DeleteSubscription deletes a subscription
-func (s *subscriptionService) DeleteSubscription(id uint) error {
-	// Verify subscription exists
-	_, err := s.subscriptionRepo.FindByID(id)
+// DeleteSubscription deletes one of the user's subscriptions. Version is
+// checked as in UpdateSubscription.
+func (s *subscriptionService) DeleteSubscription(ctx context.Context, userID, id, version uint) (err error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.DeleteSubscription")
+	defer func() { endSpan(span, err) }()
+
+	subscription, err := s.findOwned(ctx, userID, id)
 	if err != nil {
-		return errors.New("subscription not found")
+		return err
 	}
+	if version == 0 {
+		version = subscription.Version
+	}
+
+	return s.subscriptionRepo.Delete(ctx, id, version)
+}
 
-	return s.subscriptionRepo.Delete(id)
+// findOwned loads a subscription, reporting one that belongs to another user
+// as not found
+func (s *subscriptionService) findOwned(ctx context.Context, userID, id uint) (*domain.Subscription, error) {
+	subscription, err := s.subscriptionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, domain.ErrSubscriptionNotFound)
+	}
+	if subscription.UserID != userID {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	return subscription, nil
 }
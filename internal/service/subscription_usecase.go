@@ -1,7 +1,10 @@
 package service
 
 import (
+	"context"
+
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/hardiksharma/clarityfin-api/pkg/pagination"
 )
 
 // subscriptionUseCase implements the SubscriptionUseCase interface
@@ -17,26 +20,41 @@ func NewSubscriptionUseCase(subscriptionService domain.SubscriptionService) doma
 }
 
 // CreateSubscription handles subscription creation
-func (uc *subscriptionUseCase) CreateSubscription(userID uint, name string, amount float64) (*domain.Subscription, error) {
-	return uc.subscriptionService.CreateSubscription(userID, name, amount)
+func (uc *subscriptionUseCase) CreateSubscription(ctx context.Context, userID uint, name string, amount float64, currency, category string) (_ *domain.Subscription, err error) {
+	ctx, span := startSpan(ctx, "SubscriptionUseCase.CreateSubscription")
+	defer func() { endSpan(span, err) }()
+
+	return uc.subscriptionService.CreateSubscription(ctx, userID, name, amount, currency, category)
 }
 
-// GetUserSubscriptions handles retrieving user subscriptions
-func (uc *subscriptionUseCase) GetUserSubscriptions(userID uint) ([]*domain.Subscription, error) {
-	return uc.subscriptionService.GetUserSubscriptions(userID)
+// ListSubscriptions handles listing a user's subscriptions
+func (uc *subscriptionUseCase) ListSubscriptions(ctx context.Context, userID uint, filter domain.SubscriptionFilter, page pagination.Request) (_ *pagination.Page[*domain.Subscription], err error) {
+	ctx, span := startSpan(ctx, "SubscriptionUseCase.ListSubscriptions")
+	defer func() { endSpan(span, err) }()
+
+	return uc.subscriptionService.ListSubscriptions(ctx, userID, filter, page)
 }
 
-// GetSubscriptionByID handles retrieving a subscription by ID
-func (uc *subscriptionUseCase) GetSubscriptionByID(id uint) (*domain.Subscription, error) {
-	return uc.subscriptionService.GetSubscriptionByID(id)
+// GetSubscriptionByID handles retrieving one of the user's subscriptions
+func (uc *subscriptionUseCase) GetSubscriptionByID(ctx context.Context, userID, id uint) (_ *domain.Subscription, err error) {
+	ctx, span := startSpan(ctx, "SubscriptionUseCase.GetSubscriptionByID")
+	defer func() { endSpan(span, err) }()
+
+	return uc.subscriptionService.GetSubscriptionByID(ctx, userID, id)
 }
 
 // UpdateSubscription handles subscription updates
-func (uc *subscriptionUseCase) UpdateSubscription(id uint, name string, amount float64) (*domain.Subscription, error) {
-	return uc.subscriptionService.UpdateSubscription(id, name, amount)
+func (uc *subscriptionUseCase) UpdateSubscription(ctx context.Context, userID, id, version uint, changes domain.SubscriptionChanges) (_ *domain.Subscription, err error) {
+	ctx, span := startSpan(ctx, "SubscriptionUseCase.UpdateSubscription")
+	defer func() { endSpan(span, err) }()
+
+	return uc.subscriptionService.UpdateSubscription(ctx, userID, id, version, changes)
 }
 
 // DeleteSubscription handles subscription deletion
-func (uc *subscriptionUseCase) DeleteSubscription(id uint) error {
-	return uc.subscriptionService.DeleteSubscription(id)
+func (uc *subscriptionUseCase) DeleteSubscription(ctx context.Context, userID, id, version uint) (err error) {
+	ctx, span := startSpan(ctx, "SubscriptionUseCase.DeleteSubscription")
+	defer func() { endSpan(span, err) }()
+
+	return uc.subscriptionService.DeleteSubscription(ctx, userID, id, version)
 }
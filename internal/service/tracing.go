@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/hardiksharma/clarityfin-api/internal/service")
+
+// startSpan starts a span as a child of the span in ctx. Phone numbers,
+// passwords and codes must never be added as attributes.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on span, if set, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// endSendSpan ends a provider send span, recording only the class of err.
+// Provider error messages can include the destination number.
+func endSendSpan(span trace.Span, err error) {
+	if err != nil {
+		class := sendErrorClass(err)
+		span.SetAttributes(attribute.String("error.type", class))
+		span.SetStatus(codes.Error, class)
+	}
+	span.End()
+}
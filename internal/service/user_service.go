@@ -1,74 +1,203 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
+	"github.com/hardiksharma/clarityfin-api/internal/metrics"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // userService implements the UserService interface
 type userService struct {
 	userRepo  domain.UserRepository
+	uow       domain.UnitOfWork
 	jwtSecret string
+	log       *slog.Logger
+	metrics   *metrics.Metrics
 }
 
 // NewUserService creates a new instance of UserService
-func NewUserService(userRepo domain.UserRepository, jwtSecret string) domain.UserService {
+func NewUserService(userRepo domain.UserRepository, uow domain.UnitOfWork, jwtSecret string, log *slog.Logger, m *metrics.Metrics) domain.UserService {
 	return &userService{
 		userRepo:  userRepo,
+		uow:       uow,
 		jwtSecret: jwtSecret,
+		log:       log,
+		metrics:   m,
 	}
 }
 
-// Register creates a new user with hashed password
-func (s *userService) Register(phoneNumber, password string) error {
+// Register creates a new user with hashed password and preferred language
+func (s *userService) Register(ctx context.Context, phoneNumber, password, language string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.Register")
+	defer func() { endSpan(span, err) }()
+
+	phoneNumber, err = normalizePhone(phoneNumber)
+	if err != nil {
+		return err
+	}
+
 	// Check if user already exists
-	existingUser, _ := s.userRepo.FindByPhoneNumber(phoneNumber)
-	if existingUser != nil {
-		return errors.New("user already exists")
+	if err := ensureNoUser(ctx, s.userRepo, phoneNumber); err != nil {
+		return err
 	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	user, err := newUser(ctx, phoneNumber, password, language)
 	if err != nil {
 		return err
 	}
 
-	// Create user
-	user := &domain.User{
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return userExists(err)
+	}
+
+	s.log.InfoContext(ctx, "user registered", slog.Uint64("user_id", uint64(user.ID)))
+	return nil
+}
+
+// RegisterWithOTP verifies and consumes the OTP, which must have been sent
+// for registration, and creates the user in one transaction. A phone number
+// that is already registered, including by a concurrent or repeated request,
+// gets ErrUserAlreadyExists.
+func (s *userService) RegisterWithOTP(ctx context.Context, phoneNumber, password, language, code string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.RegisterWithOTP")
+	defer func() { endSpan(span, err) }()
+
+	phoneNumber, err = normalizePhone(phoneNumber)
+	if err != nil {
+		return err
+	}
+
+	user, err := newUser(ctx, phoneNumber, password, language)
+	if err != nil {
+		return err
+	}
+
+	// Consuming the OTP and creating the user commit together, so a failed
+	// insert leaves the OTP unused. The OTP is checked first so that only a
+	// caller holding a valid code learns whether the number is registered; a
+	// concurrent registration that gets past the check fails on the unique
+	// index.
+	err = s.uow.Do(ctx, domain.IsolationDefault, func(ctx context.Context, repos domain.Repositories) error {
+		if err := repos.OTPs.Consume(ctx, phoneNumber, code, domain.OTPPurposeRegistration); err != nil {
+			return err
+		}
+		if err := ensureNoUser(ctx, repos.Users, phoneNumber); err != nil {
+			return err
+		}
+		if err := repos.Users.Create(ctx, user); err != nil {
+			return userExists(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.InfoContext(ctx, "user registered", slog.Uint64("user_id", uint64(user.ID)), slog.Bool("phone_verified", true))
+	return nil
+}
+
+// ensureNoUser returns ErrUserAlreadyExists if phoneNumber is registered
+func ensureNoUser(ctx context.Context, users domain.UserRepository, phoneNumber string) error {
+	_, err := users.FindByPhoneNumber(ctx, phoneNumber)
+	if err == nil {
+		return domain.ErrUserAlreadyExists
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+// userExists translates a unique violation on users.phone_number, the only
+// unique column besides the key, into ErrUserAlreadyExists
+func userExists(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.ErrUserAlreadyExists
+	}
+	return err
+}
+
+// newUser builds a user with a bcrypt-hashed password
+func newUser(ctx context.Context, phoneNumber, password, language string) (*domain.User, error) {
+	_, span := startSpan(ctx, "bcrypt.GenerateFromPassword")
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.User{
 		PhoneNumber: phoneNumber,
 		Password:    string(hashedPassword),
-	}
+		Language:    language,
+	}, nil
+}
 
-	return s.userRepo.Create(user)
+// comparePassword checks password against a bcrypt hash. It is traced
+// because bcrypt dominates login latency.
+func comparePassword(ctx context.Context, hash, password string) error {
+	_, span := startSpan(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
 // Authenticate validates user credentials and returns user if valid
-func (s *userService) Authenticate(phoneNumber, password string) (*domain.User, error) {
-	user, err := s.userRepo.FindByPhoneNumber(phoneNumber)
+func (s *userService) Authenticate(ctx context.Context, phoneNumber, password string) (_ *domain.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.Authenticate")
+	defer func() { endSpan(span, err) }()
+
+	phoneNumber, err = normalizePhone(phoneNumber)
+	if err != nil {
+		s.metrics.Login(metrics.OutcomeFailure)
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	user, err := s.userRepo.FindByPhoneNumber(ctx, phoneNumber)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.metrics.Login(metrics.OutcomeFailure)
+		s.log.InfoContext(ctx, "login failed", slog.String("phone_number", phoneNumber), slog.String("reason", "unknown user"))
+		return nil, domain.ErrInvalidCredentials
+	}
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		s.metrics.Login(metrics.OutcomeFailure)
+		return nil, err
 	}
 
 	// Compare password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, errors.New("invalid credentials")
+	if err := comparePassword(ctx, user.Password, password); err != nil {
+		s.metrics.Login(metrics.OutcomeFailure)
+		s.log.InfoContext(ctx, "login failed", slog.Uint64("user_id", uint64(user.ID)), slog.String("reason", "wrong password"))
+		return nil, domain.ErrInvalidCredentials
 	}
 
+	s.metrics.Login(metrics.OutcomeSuccess)
 	return user, nil
 }
 
 // GetByID retrieves a user by ID
-func (s *userService) GetByID(id uint) (*domain.User, error) {
-	return s.userRepo.FindByID(id)
+func (s *userService) GetByID(ctx context.Context, id uint) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, domain.ErrUserNotFound)
+	}
+	return user, nil
 }
 
 // GetByPhoneNumber retrieves a user by phone number
-func (s *userService) GetByPhoneNumber(phoneNumber string) (*domain.User, error) {
-	return s.userRepo.FindByPhoneNumber(phoneNumber)
+func (s *userService) GetByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.User, error) {
+	user, err := s.userRepo.FindByPhoneNumber(ctx, phoneNumber)
+	if err != nil {
+		return nil, notFound(err, domain.ErrUserNotFound)
+	}
+	return user, nil
 }
 
 // GenerateJWT generates a JWT token for a user
@@ -1,29 +1,50 @@
 package service
 
 import (
+	"context"
 	"github.com/hardiksharma/clarityfin-api/internal/domain"
 )
 
 // userUseCase implements the UserUseCase interface
 type userUseCase struct {
-	userService domain.UserService
+	userService              domain.UserService
+	requirePhoneVerification bool
 }
 
-// NewUserUseCase creates a new instance of UserUseCase
-func NewUserUseCase(userService domain.UserService) domain.UserUseCase {
+// NewUserUseCase creates a new instance of UserUseCase. When
+// requirePhoneVerification is set, accounts can only be created with an OTP.
+func NewUserUseCase(userService domain.UserService, requirePhoneVerification bool) domain.UserUseCase {
 	return &userUseCase{
-		userService: userService,
+		userService:              userService,
+		requirePhoneVerification: requirePhoneVerification,
 	}
 }
 
 // Register handles user registration
-func (uc *userUseCase) Register(phoneNumber, password string) error {
-	return uc.userService.Register(phoneNumber, password)
+func (uc *userUseCase) Register(ctx context.Context, phoneNumber, password, language string) (err error) {
+	ctx, span := startSpan(ctx, "UserUseCase.Register")
+	defer func() { endSpan(span, err) }()
+
+	if uc.requirePhoneVerification {
+		return domain.ErrPhoneVerificationRequired
+	}
+	return uc.userService.Register(ctx, phoneNumber, password, language)
+}
+
+// RegisterWithOTP handles user registration with OTP verification
+func (uc *userUseCase) RegisterWithOTP(ctx context.Context, phoneNumber, password, language, code string) (err error) {
+	ctx, span := startSpan(ctx, "UserUseCase.RegisterWithOTP")
+	defer func() { endSpan(span, err) }()
+
+	return uc.userService.RegisterWithOTP(ctx, phoneNumber, password, language, code)
 }
 
 // Login handles user authentication and returns JWT token
-func (uc *userUseCase) Login(phoneNumber, password string) (string, error) {
-	user, err := uc.userService.Authenticate(phoneNumber, password)
+func (uc *userUseCase) Login(ctx context.Context, phoneNumber, password string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "UserUseCase.Login")
+	defer func() { endSpan(span, err) }()
+
+	user, err := uc.userService.Authenticate(ctx, phoneNumber, password)
 	if err != nil {
 		return "", err
 	}
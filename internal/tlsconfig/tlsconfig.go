@@ -0,0 +1,194 @@
+// Package tlsconfig builds the server's tls.Config from configuration and
+// keeps the certificate current when the files on disk are replaced, e.g.
+// by cert-manager or certbot, without restarting the process.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hardiksharma/clarityfin-api/internal/config"
+)
+
+// minVersions maps configured minimum versions to their constants
+var minVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// New returns a TLS configuration serving the certificate from cfg and the
+// reloader behind it. Call Reloader.Watch to pick up replaced files.
+func New(cfg config.TLSConfig, log *slog.Logger) (*tls.Config, *Reloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, nil, errors.New("tls: cert_file and key_file are required")
+	}
+
+	minVersion, ok := minVersions[cfg.MinVersion]
+	if !ok {
+		return nil, nil, fmt.Errorf("tls: unsupported min_version %q (expected 1.2 or 1.3)", cfg.MinVersion)
+	}
+
+	cipherSuites, err := parseCipherSuites(cfg.CipherSuites)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reloader, err := NewReloader(cfg.CertFile, cfg.KeyFile, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		GetCertificate: reloader.GetCertificate,
+	}, reloader, nil
+}
+
+// WithClientCA returns a copy of base that requires clients to present a
+// certificate signed by a CA in caFile
+func WithClientCA(base *tls.Config, caFile string) (*tls.Config, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: reading client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("tls: no certificates found in client CA file %s", caFile)
+	}
+
+	mtls := base.Clone()
+	mtls.ClientCAs = pool
+	mtls.ClientAuth = tls.RequireAndVerifyClientCert
+	return mtls, nil
+}
+
+// parseCipherSuites resolves cipher suite names. Only suites Go considers
+// secure are accepted. An empty list keeps Go's defaults. TLS 1.3 suites
+// are not configurable and are always enabled.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	suites := tls.CipherSuites()
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(suites, func(s *tls.CipherSuite) bool { return s.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("tls: unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, suites[i].ID)
+	}
+	return ids, nil
+}
+
+// Reloader serves a certificate loaded from disk and reloads it when the
+// certificate or key file changes
+type Reloader struct {
+	certFile string
+	keyFile  string
+	log      *slog.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time // latest modification time of the two files
+}
+
+// NewReloader loads the key pair and returns a Reloader serving it
+func NewReloader(certFile, keyFile string, log *slog.Logger) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, log: log}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Watch checks the files every interval until ctx is done and reloads the
+// certificate when either has changed. A pair that fails to load, e.g.
+// because only one file has been replaced so far, is logged and the
+// previous certificate stays in use until the next check.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime, err := r.latestModTime()
+			if err != nil {
+				r.log.Error("failed to check TLS certificate", slog.String("error", err.Error()))
+				continue
+			}
+
+			// Any change counts: copies may keep an older timestamp
+			r.mu.RLock()
+			changed := !modTime.Equal(r.modTime)
+			r.mu.RUnlock()
+			if !changed {
+				continue
+			}
+
+			if err := r.reload(); err != nil {
+				r.log.Error("failed to reload TLS certificate", slog.String("error", err.Error()))
+				continue
+			}
+			r.log.Info("TLS certificate reloaded", slog.String("cert_file", r.certFile))
+		}
+	}
+}
+
+// reload loads the key pair and swaps it in
+func (r *Reloader) reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("tls: loading key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// latestModTime returns the later modification time of the two files
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("tls: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
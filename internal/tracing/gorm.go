@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+var tracer = otel.Tracer("github.com/hardiksharma/clarityfin-api/internal/tracing")
+
+// gormPlugin starts a client span for every GORM operation as a child of the
+// span in the statement's context
+type gormPlugin struct {
+	system string
+}
+
+// GormPlugin returns a GORM plugin that traces queries. Install it with
+// db.Use. SQL is recorded without bound parameters.
+func GormPlugin() gorm.Plugin {
+	return &gormPlugin{}
+}
+
+func (p *gormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	p.system = db.Dialector.Name()
+	cb := db.Callback()
+
+	errs := []error{
+		cb.Create().Before("*").Register("tracing:before_create", p.start("create")),
+		cb.Create().After("*").Register("tracing:after_create", p.end),
+		cb.Query().Before("*").Register("tracing:before_query", p.start("query")),
+		cb.Query().After("*").Register("tracing:after_query", p.end),
+		cb.Update().Before("*").Register("tracing:before_update", p.start("update")),
+		cb.Update().After("*").Register("tracing:after_update", p.end),
+		cb.Delete().Before("*").Register("tracing:before_delete", p.start("delete")),
+		cb.Delete().After("*").Register("tracing:after_delete", p.end),
+		cb.Row().Before("*").Register("tracing:before_row", p.start("row")),
+		cb.Row().After("*").Register("tracing:after_row", p.end),
+		cb.Raw().Before("*").Register("tracing:before_raw", p.start("raw")),
+		cb.Raw().After("*").Register("tracing:after_raw", p.end),
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *gormPlugin) start(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			// Skip queries outside a traced request, e.g. migrations
+			return
+		}
+
+		name := "db." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+
+		_, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameKey.String(p.system),
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(db.Statement.Table),
+			))
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *gormPlugin) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,129 @@
+// Package pagination implements the keyset pagination contract shared by all
+// list endpoints: a page size capped at MaxLimit, a sort of the form "field"
+// or "-field", and opaque cursors pointing after the last item of a page.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Page size limits
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded or was issued
+// for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidSort is returned when the sort field is not allowed
+var ErrInvalidSort = errors.New("invalid sort")
+
+// Sort orders a listing by one field, with the item ID as a tiebreaker
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// String returns the sort in its query form, e.g. "-created_at"
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// ParseSort parses "field" or "-field" (descending). An empty value yields
+// def; fields not in allowed are rejected with ErrInvalidSort.
+func ParseSort(raw string, allowed []string, def Sort) (Sort, error) {
+	if raw == "" {
+		return def, nil
+	}
+
+	sort := Sort{Field: strings.TrimPrefix(raw, "-"), Desc: strings.HasPrefix(raw, "-")}
+	if !slices.Contains(allowed, sort.Field) {
+		return Sort{}, ErrInvalidSort
+	}
+	return sort, nil
+}
+
+// Cursor identifies the last item of a page: its sort value, formatted by
+// the repository, and its ID
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// Encode returns the cursor's opaque string form
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses an opaque cursor string
+func decodeCursor(raw string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Request describes one page of a listing
+type Request struct {
+	Limit int
+	Sort  Sort
+	// After is the cursor of the previous page's last item, nil for the
+	// first page
+	After *Cursor
+}
+
+// NewRequest builds a page request. A non-positive limit selects
+// DefaultLimit and larger limits are capped at MaxLimit. A cursor must have
+// been issued for the same sort.
+func NewRequest(limit int, sort Sort, cursor string) (Request, error) {
+	switch {
+	case limit <= 0:
+		limit = DefaultLimit
+	case limit > MaxLimit:
+		limit = MaxLimit
+	}
+
+	request := Request{Limit: limit, Sort: sort}
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return Request{}, err
+		}
+		if after.Sort != sort.String() {
+			return Request{}, ErrInvalidCursor
+		}
+		request.After = after
+	}
+
+	return request, nil
+}
+
+// Page is one page of results. Total counts every item matching the filters,
+// not just this page. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	Total      int64
+	NextCursor string
+}
+
+// HasMore reports whether another page follows
+func (p *Page[T]) HasMore() bool {
+	return p.NextCursor != ""
+}
@@ -0,0 +1,74 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/nyaruka/phonenumbers"
+)
+
+// ErrInvalidNumber is returned when a phone number cannot be parsed or is not
+// a valid number for its region
+var ErrInvalidNumber = errors.New("invalid phone number")
+
+var (
+	mu            sync.RWMutex
+	defaultRegion = "US"
+)
+
+// SetDefaultRegion sets the ISO 3166-1 region (e.g. "IN") used to interpret
+// numbers that are not written with a leading "+" country code
+func SetDefaultRegion(region string) error {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if phonenumbers.GetCountryCodeForRegion(region) == 0 {
+		return fmt.Errorf("unsupported phone region %q", region)
+	}
+
+	mu.Lock()
+	defaultRegion = region
+	mu.Unlock()
+	return nil
+}
+
+// DefaultRegion returns the region used for numbers without a country code
+func DefaultRegion() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultRegion
+}
+
+// Normalize parses a phone number using the default region and returns it in
+// E.164 format, e.g. "+91 98765 43210" and "9876543210" both become
+// "+919876543210" when the default region is IN
+func Normalize(raw string) (string, error) {
+	return NormalizeWithRegion(raw, DefaultRegion())
+}
+
+// NormalizeWithRegion is like Normalize but uses the given region
+func NormalizeWithRegion(raw, region string) (string, error) {
+	num, err := phonenumbers.Parse(strings.TrimSpace(raw), region)
+	if err != nil {
+		return "", ErrInvalidNumber
+	}
+	if !phonenumbers.IsValidNumber(num) {
+		return "", ErrInvalidNumber
+	}
+	return phonenumbers.Format(num, phonenumbers.E164), nil
+}
+
+// IsValid reports whether raw is a valid phone number in the default region
+func IsValid(raw string) bool {
+	_, err := Normalize(raw)
+	return err == nil
+}
+
+// RegisterValidation registers the "phone" tag on a validator so request
+// structs can declare `validate:"phone"`
+func RegisterValidation(v *validator.Validate) error {
+	return v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		return IsValid(fl.Field().String())
+	})
+}
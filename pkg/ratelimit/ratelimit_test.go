@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newTestStore returns a MemoryStore whose clock only moves when the
+// returned function is called
+func newTestStore() (*MemoryStore, func(time.Duration)) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	return store, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestMemoryStoreBurstThenRefill(t *testing.T) {
+	store, advance := newTestStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 1, Period: time.Second, Burst: 3}
+
+	for i := range 3 {
+		result, _ := store.Take(ctx, "k", limit)
+		if !result.Allowed || result.Remaining != 2-i {
+			t.Fatalf("take %d = %+v, want allowed with %d remaining", i+1, result, 2-i)
+		}
+	}
+
+	result, _ := store.Take(ctx, "k", limit)
+	if result.Allowed || result.RetryAfter != time.Second {
+		t.Fatalf("take over burst = %+v, want refused with RetryAfter 1s", result)
+	}
+	if result.Limit != 3 || result.Reset != 3*time.Second {
+		t.Errorf("Limit, Reset = %d, %v; want 3, 3s", result.Limit, result.Reset)
+	}
+
+	advance(time.Second)
+	if result, _ := store.Take(ctx, "k", limit); !result.Allowed {
+		t.Errorf("take after refill = %+v, want allowed", result)
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	store, _ := newTestStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 1, Period: time.Minute}
+
+	if result, _ := store.Take(ctx, "a", limit); !result.Allowed {
+		t.Fatalf("first take for a = %+v, want allowed", result)
+	}
+	if result, _ := store.Take(ctx, "a", limit); result.Allowed {
+		t.Errorf("second take for a = %+v, want refused", result)
+	}
+	if result, _ := store.Take(ctx, "b", limit); !result.Allowed {
+		t.Errorf("first take for b = %+v, want allowed", result)
+	}
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	store, advance := newTestStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 10, Period: time.Second}
+
+	store.Take(ctx, "k", limit)
+	advance(sweepInterval)
+	store.Take(ctx, "other", limit)
+
+	if _, ok := store.buckets["k"]; ok {
+		t.Error("refilled bucket was not swept")
+	}
+}
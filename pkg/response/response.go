@@ -11,7 +11,23 @@ type Response struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Error   *ErrorBody  `json:"error,omitempty"`
+}
+
+// ErrorBody describes a failed request. Code is a stable, machine-readable
+// identifier; Message is human-readable and may change.
+type ErrorBody struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// FieldError describes why a single request field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 // Success sends a successful response
@@ -23,30 +39,37 @@ func Success(c *gin.Context, data interface{}, message string) {
 	})
 }
 
-// Error sends an error response
-func Error(c *gin.Context, statusCode int, message string) {
-	c.JSON(statusCode, Response{
-		Success: false,
-		Error:   message,
+// Created sends a 201 Created response for a newly created resource
+func Created(c *gin.Context, data interface{}, message string) {
+	c.JSON(http.StatusCreated, Response{
+		Success: true,
+		Message: message,
+		Data:    data,
 	})
 }
 
-// BadRequest sends a 400 Bad Request response
-func BadRequest(c *gin.Context, message string) {
-	Error(c, http.StatusBadRequest, message)
+// Error sends an error response and aborts the remaining handlers. The
+// request ID set by the RequestID middleware is included so clients can
+// quote it in support requests.
+func Error(c *gin.Context, statusCode int, code, message string, details ...FieldError) {
+	c.AbortWithStatusJSON(statusCode, Response{
+		Success: false,
+		Error: &ErrorBody{
+			Code:      code,
+			Message:   message,
+			Details:   details,
+			RequestID: c.GetString("request_id"),
+		},
+	})
 }
 
 // Unauthorized sends a 401 Unauthorized response
-func Unauthorized(c *gin.Context, message string) {
-	Error(c, http.StatusUnauthorized, message)
-}
-
-// NotFound sends a 404 Not Found response
-func NotFound(c *gin.Context, message string) {
-	Error(c, http.StatusNotFound, message)
+func Unauthorized(c *gin.Context, code, message string) {
+	Error(c, http.StatusUnauthorized, code, message)
 }
 
-// InternalServerError sends a 500 Internal Server Error response
-func InternalServerError(c *gin.Context, message string) {
-	Error(c, http.StatusInternalServerError, message)
+// InternalServerError sends a 500 Internal Server Error response without
+// revealing the cause
+func InternalServerError(c *gin.Context) {
+	Error(c, http.StatusInternalServerError, "internal_error", "Internal server error")
 }
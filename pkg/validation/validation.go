@@ -0,0 +1,157 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/hardiksharma/clarityfin-api/pkg/phone"
+)
+
+// maxMoney bounds monetary amounts well below the point where float64 loses
+// cent precision
+const maxMoney = 1e12
+
+// Validator validates request structs using their `validate` tags. It
+// implements gin's binding.StructValidator so it can replace gin's default,
+// which only reads `binding` tags.
+type Validator struct {
+	validate *validator.Validate
+}
+
+var _ binding.StructValidator = (*Validator)(nil)
+
+// New creates a Validator with the custom "phone", "currency" and "money"
+// tags registered. Errors report fields by their JSON names, or query
+// parameter names for query structs.
+func New() (*Validator, error) {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.SetTagName("validate")
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		tag := field.Tag.Get("json")
+		if tag == "" {
+			tag = field.Tag.Get("form")
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	if err := phone.RegisterValidation(v); err != nil {
+		return nil, err
+	}
+	if err := v.RegisterValidation("money", validateMoney); err != nil {
+		return nil, err
+	}
+	// Currencies are ISO 4217 codes such as "INR" or "USD"
+	v.RegisterAlias("currency", "iso4217")
+
+	return &Validator{validate: v}, nil
+}
+
+// ValidateStruct validates a struct or pointer to struct, and each element
+// of a slice or array as gin's default validator does. Other values are
+// accepted as-is. Errors in elements are returned as a
+// binding.SliceValidationError with one entry per element, nil for elements
+// that passed, so entries line up with element indexes.
+func (v *Validator) ValidateStruct(obj any) error {
+	if obj == nil {
+		return nil
+	}
+
+	value := reflect.ValueOf(obj)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		if value.Elem().Kind() != reflect.Struct {
+			return v.ValidateStruct(value.Elem().Interface())
+		}
+		return v.validate.Struct(obj)
+	case reflect.Struct:
+		return v.validate.Struct(obj)
+	case reflect.Slice, reflect.Array:
+		errs := make(binding.SliceValidationError, value.Len())
+		failed := false
+		for i := range errs {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				errs[i] = err
+				failed = true
+			}
+		}
+		if !failed {
+			return nil
+		}
+		return errs
+	default:
+		return nil
+	}
+}
+
+// Engine returns the underlying go-playground validator
+func (v *Validator) Engine() any {
+	return v.validate
+}
+
+// validateMoney accepts finite amounts with at most two decimal places
+func validateMoney(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Kind() != reflect.Float32 && field.Kind() != reflect.Float64 {
+		return false
+	}
+
+	amount := field.Float()
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || math.Abs(amount) >= maxMoney {
+		return false
+	}
+	cents := amount * 100
+	return math.Abs(cents-math.Round(cents)) < 1e-6
+}
+
+// Message returns a human-readable description of why a field failed
+// validation, without the field name
+func Message(fe validator.FieldError) string {
+	isString := fe.Kind() == reflect.String
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if isString {
+			return fmt.Sprintf("must be at least %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if isString {
+			return fmt.Sprintf("must be at most %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "len":
+		if isString {
+			return fmt.Sprintf("must be exactly %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must have exactly %s items", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "numeric":
+		return "must contain only digits"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "phone":
+		return "must be a valid phone number"
+	case "currency":
+		return "must be an ISO 4217 currency code such as INR"
+	case "money":
+		return "must be an amount with at most 2 decimal places"
+	case "bcp47_language_tag":
+		return "must be a BCP 47 language tag such as en or hi-IN"
+	default:
+		return fmt.Sprintf("failed the %q check", fe.Tag())
+	}
+}
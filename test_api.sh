@@ -33,7 +33,7 @@ echo -e "\n${YELLOW}1. Testing OTP Send${NC}"
 echo "--------------------------------"
 SEND_OTP_RESPONSE=$(curl -s -X POST $BASE_URL/otp/send \
   -H "Content-Type: application/json" \
-  -d '{"phone_number": "+1234567890"}')
+  -d '{"phone_number": "+919876543210"}')
 
 if echo "$SEND_OTP_RESPONSE" | grep -q '"success":true'; then
     echo -e "${GREEN}✅ OTP sent successfully${NC}"
@@ -49,7 +49,7 @@ echo "--------------------------------"
 # For testing, we'll use a mock OTP (you can check the server logs for the actual OTP)
 REGISTER_OTP_RESPONSE=$(curl -s -X POST $BASE_URL/auth/register/otp \
   -H "Content-Type: application/json" \
-  -d '{"phone_number": "+1234567890", "password": "testpassword", "otp_code": "123456"}')
+  -d '{"phone_number": "+919876543210", "password": "testpassword", "otp_code": "123456"}')
 
 if echo "$REGISTER_OTP_RESPONSE" | grep -q '"success":true'; then
     echo -e "${GREEN}✅ Registration with OTP successful${NC}"
@@ -63,7 +63,7 @@ echo -e "\n${YELLOW}3. Testing Duplicate Registration${NC}"
 echo "--------------------------------"
 DUPLICATE_RESPONSE=$(curl -s -X POST $BASE_URL/auth/register \
   -H "Content-Type: application/json" \
-  -d '{"phone_number": "+1234567890", "password": "testpassword"}')
+  -d '{"phone_number": "+919876543210", "password": "testpassword"}')
 
 if echo "$DUPLICATE_RESPONSE" | grep -q '"success":false'; then
     echo -e "${GREEN}✅ Duplicate registration properly handled${NC}"
@@ -77,7 +77,7 @@ echo -e "\n${YELLOW}4. Testing User Login${NC}"
 echo "--------------------------------"
 LOGIN_RESPONSE=$(curl -s -X POST $BASE_URL/auth/login \
   -H "Content-Type: application/json" \
-  -d '{"phone_number": "+1234567890", "password": "testpassword"}')
+  -d '{"phone_number": "+919876543210", "password": "testpassword"}')
 
 if echo "$LOGIN_RESPONSE" | grep -q '"success":true'; then
     echo -e "${GREEN}✅ Login successful${NC}"
@@ -100,7 +100,7 @@ echo -e "\n${YELLOW}5. Testing Invalid Login${NC}"
 echo "--------------------------------"
 INVALID_LOGIN_RESPONSE=$(curl -s -X POST $BASE_URL/auth/login \
   -H "Content-Type: application/json" \
-  -d '{"phone_number": "+1234567890", "password": "wrongpassword"}')
+  -d '{"phone_number": "+919876543210", "password": "wrongpassword"}')
 
 if echo "$INVALID_LOGIN_RESPONSE" | grep -q '"success":false'; then
     echo -e "${GREEN}✅ Invalid login properly handled${NC}"